@@ -20,12 +20,19 @@ func (a *App) showTemplateHelp() {
 
 🔹 기본 CSV 컬럼 변수:
    {{.column_name}} - CSV의 각 컬럼 값 (예: {{.name}}, {{.email}})
+   int/float/decimal 컬럼은 숫자, date 컬럼은 날짜, 빈 값은 nil로 전달됩니다
+   {{.norm.column_name}} - 정규화된 문자열 (이전 버전의 {{.column_name}})
+   {{.raw.column_name}} - CSV에 적힌 원래 문자열
 
 🔹 내장 템플릿 함수:
    {{ dateFormat "2006-01-02" .birth }}     - 날짜 포맷 변경
    {{ toE164KR .phone }}                    - 한국 전화번호를 E164 형식으로
    {{ mask .password }}                     - 민감한 데이터 마스킹
    {{ hash .email }}                        - 문자열 해시값 생성
+   {{ addDays 30 .joined | dateFormat "2006-01-02" }} - 날짜 계산
+   {{ fixed 2 .amount }}                    - 소수점 자릿수 고정
+   {{ json .amount }}                       - JSON 값 (숫자는 따옴표 없이, 빈 값은 null)
+   {{ .memo | default "-" }}                - 빈 값일 때 대신 쓸 값
    {{ now }}                                - 현재 시간
 
 🔹 조건부 표현:
@@ -83,7 +90,7 @@ func (a *App) showSchemaConfigDialog() {
 			nameEntry.SetPlaceHolder("컬럼명 (예: user_name, email, phone)")
 			
 			// 타입 선택
-			typeSelect := widget.NewSelect([]string{"string", "int", "float", "decimal", "date"}, nil)
+			typeSelect := widget.NewSelect([]string{"string", "int", "float", "decimal", "date", "datetime", "bool", "email", "uuid", "json", "list"}, nil)
 			typeSelect.SetSelected(column.Type)
 			
 			// 필수 여부
@@ -185,7 +185,7 @@ func (a *App) onRender() {
 				continue
 			}
 			
-			requestData, err := renderer.Render(result.Data)
+			requestData, err := renderer.Render(result)
 			if err != nil {
 				a.logMessage(fmt.Sprintf("행 %d: 템플릿 렌더링 실패: %v", i+1, err))
 				continue
@@ -218,7 +218,10 @@ func (a *App) onRun() {
 		defer func() {
 			a.state.mu.Lock()
 			a.state.IsRunning = false
+			a.state.IsPaused = false
+			a.state.IsStopping = false
 			a.state.Cancel = nil
+			a.state.Runner = nil
 			a.state.mu.Unlock()
 			a.updateButtons()
 		}()
@@ -284,13 +287,24 @@ func (a *App) onRun() {
 			return
 		}
 		defer loggerInstance.Close()
+
+		// Stream failed rows to disk while running
+		if a.state.ExportFailed != "" {
+			if err := loggerInstance.StartFailedRowExport(a.state.ExportFailed, false); err != nil {
+				a.logMessage(fmt.Sprintf("실패한 행 파일 생성 실패: %v", err))
+				a.setStatus("실행 실패")
+				return
+			}
+		}
 		
 		// Create context
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel() // Ensure context is always canceled
 		a.state.mu.Lock()
 		a.state.Cancel = cancel
+		a.state.Runner = runnerInstance
 		a.state.mu.Unlock()
+		a.logMessage("실행 중에도 동시성/속도를 입력하고 Enter를 누르면 바로 적용됩니다")
 		
 		// Create CSV reader
 		csvReader := reader.NewCSVReader(schema, a.state.CSVFile)
@@ -307,7 +321,7 @@ func (a *App) onRun() {
 		
 		// Start CSV reading
 		go func() {
-			if err := csvReader.ReadRows(tasksChan); err != nil {
+			if err := csvReader.ReadRows(ctx, tasksChan); err != nil {
 				a.logMessage(fmt.Sprintf("CSV 읽기 오류: %v", err))
 				cancel()
 			}
@@ -346,25 +360,98 @@ func (a *App) onRun() {
 		a.progressBar.SetValue(1.0)
 		a.logMessage(fmt.Sprintf("실행 완료 - 총: %d, 성공: %d, 실패: %d", 
 			result.TotalRows, result.SuccessRows, result.FailedRows))
+		if result.NotAttempted > 0 {
+			a.logMessage(fmt.Sprintf("중지로 보내지 않은 행: %d (이어하기로 다시 보낼 수 있습니다)", result.NotAttempted))
+		}
 		a.setStatus(fmt.Sprintf("완료: 성공 %d, 실패 %d", result.SuccessRows, result.FailedRows))
 		
 		// Export failed rows if requested
 		if a.state.ExportFailed != "" && loggerInstance.GetFailedRowCount() > 0 {
-			if err := loggerInstance.ExportFailedRows(a.state.ExportFailed); err != nil {
-				a.logMessage(fmt.Sprintf("실패한 행 내보내기 오류: %v", err))
-			} else {
-				a.logMessage(fmt.Sprintf("실패한 행 저장됨: %s", a.state.ExportFailed))
-			}
+			a.logMessage(fmt.Sprintf("실패한 행 저장됨: %s", a.state.ExportFailed))
 		}
 	}()
 }
 
+// stopGraceTimeout is how long in-flight requests may finish after the first stop click
+const stopGraceTimeout = 30 * time.Second
+
+// onStop stops in two steps like the CLI: the first click stops sending new
+// requests and lets in-flight ones finish; a second click (or the grace
+// timeout) cancels them
 func (a *App) onStop() {
 	a.state.mu.Lock()
-	if a.state.Cancel != nil {
+	defer a.state.mu.Unlock()
+
+	if a.state.Cancel == nil {
+		return
+	}
+	if a.state.IsStopping || a.state.Runner == nil {
 		a.state.Cancel()
-		a.logMessage("중지 요청됨...")
+		a.logMessage("즉시 중지: 진행 중인 요청을 취소합니다")
 		a.setStatus("중지 중...")
+		return
+	}
+
+	a.state.IsStopping = true
+	a.state.Runner.Stop()
+	cancel := a.state.Cancel
+	time.AfterFunc(stopGraceTimeout, cancel)
+	a.logMessage(fmt.Sprintf("중지 요청됨: 진행 중인 요청을 최대 %v 기다립니다 (한 번 더 누르면 즉시 중지)", stopGraceTimeout))
+	a.setStatus("중지 중...")
+}
+
+func (a *App) onPause() {
+	a.state.mu.Lock()
+	runnerInstance := a.state.Runner
+	if runnerInstance == nil {
+		a.state.mu.Unlock()
+		return
+	}
+	if a.state.IsPaused {
+		runnerInstance.Resume()
+		a.state.IsPaused = false
+		a.logMessage("재개: 남은 행을 이어서 보냅니다")
+		a.setStatus("실행 중...")
+	} else {
+		runnerInstance.Pause()
+		a.state.IsPaused = true
+		a.logMessage("일시정지: 진행 중인 요청만 마무리하고 새 행은 보내지 않습니다")
+		a.setStatus("일시정지됨")
 	}
 	a.state.mu.Unlock()
-} 
+	a.updateButtons()
+}
+
+// onApplySettings applies the concurrency and rate entries to the current run
+func (a *App) onApplySettings(string) {
+	a.state.mu.RLock()
+	runnerInstance := a.state.Runner
+	a.state.mu.RUnlock()
+	if runnerInstance == nil {
+		return
+	}
+
+	if concurrency, err := strconv.Atoi(a.concurrencyEntry.Text); err == nil && concurrency > 0 {
+		runnerInstance.SetConcurrency(concurrency)
+		a.logMessage(fmt.Sprintf("동시성 변경: %d", concurrency))
+	} else {
+		a.logMessage(fmt.Sprintf("동시성 값이 잘못됨: %s", a.concurrencyEntry.Text))
+	}
+
+	rateText := strings.TrimSpace(a.rateLimitEntry.Text)
+	var rateLimitValue float64
+	if rateText != "" && rateText != "0" {
+		var err error
+		rateLimitValue, err = strconv.ParseFloat(strings.TrimSuffix(rateText, "/s"), 64)
+		if err != nil || rateLimitValue < 0 {
+			a.logMessage(fmt.Sprintf("속도 값이 잘못됨 (예: 5/s): %s", rateText))
+			return
+		}
+	}
+	runnerInstance.SetRateLimit(rateLimitValue)
+	if rateLimitValue > 0 {
+		a.logMessage(fmt.Sprintf("속도 변경: %g/s", rateLimitValue))
+	} else {
+		a.logMessage("속도 변경: 제한 없음")
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	fyne "fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
+
+	"csvfire/internal/runner"
 )
 
 type AppState struct {
@@ -13,18 +15,21 @@ type AppState struct {
 	CSVFile     string
 	RequestFile string
 	LogDir      string
-	
+
 	// Settings
-	Concurrency int
-	RateLimit   string
-	Timeout     string
-	Resume      bool
+	Concurrency  int
+	RateLimit    string
+	Timeout      string
+	Resume       bool
 	ExportFailed string
-	
+
 	// Runtime
-	IsRunning bool
-	Cancel    context.CancelFunc
-	mu        sync.RWMutex
+	IsRunning  bool
+	IsPaused   bool
+	IsStopping bool // Stop was clicked once; in-flight requests are finishing
+	Cancel     context.CancelFunc
+	Runner     *runner.Runner // Runner of the current run, for pause and live settings
+	mu         sync.RWMutex
 }
 
 // SchemaColumn represents a column in the schema editor
@@ -74,6 +79,7 @@ type App struct {
 	renderBtn   *widget.Button
 	runBtn      *widget.Button
 	stopBtn     *widget.Button
+	pauseBtn    *widget.Button
 	
 	progressBar   *widget.ProgressBar
 	statusLabel   *widget.Label
@@ -71,10 +71,12 @@ func (a *App) createCompactControlPanel() *fyne.Container {
 	a.concurrencyEntry = widget.NewEntry()
 	a.concurrencyEntry.SetText(strconv.Itoa(a.state.Concurrency))
 	a.concurrencyEntry.Resize(fyne.NewSize(60, 30))
+	a.concurrencyEntry.OnSubmitted = a.onApplySettings
 	
 	a.rateLimitEntry = widget.NewEntry()
 	a.rateLimitEntry.SetText(a.state.RateLimit)
 	a.rateLimitEntry.Resize(fyne.NewSize(60, 30))
+	a.rateLimitEntry.OnSubmitted = a.onApplySettings
 	
 	a.timeoutEntry = widget.NewEntry()
 	a.timeoutEntry.SetText(a.state.Timeout)
@@ -96,6 +98,7 @@ func (a *App) createCompactControlPanel() *fyne.Container {
 	a.renderBtn = widget.NewButton("👁️ 미리보기", a.onRender)
 	a.runBtn = widget.NewButton("🚀 실행", a.onRun)
 	a.stopBtn = widget.NewButton("⏹️ 중지", a.onStop)
+	a.pauseBtn = widget.NewButton("⏸️ 일시정지", a.onPause)
 
 	// 진행률 및 상태
 	a.progressBar = widget.NewProgressBar()
@@ -133,7 +136,7 @@ func (a *App) createCompactControlPanel() *fyne.Container {
 		widget.NewLabel("속도:"), a.rateLimitEntry,  
 		widget.NewLabel("타임아웃:"), a.timeoutEntry,
 		widget.NewSeparator(),
-		a.validateBtn, a.renderBtn, a.runBtn, a.stopBtn,
+		a.validateBtn, a.renderBtn, a.runBtn, a.pauseBtn, a.stopBtn,
 	)
 
 	// 세 번째 행: 진행률
@@ -194,6 +197,7 @@ func (a *App) createMainLogSection() *fyne.Container {
 func (a *App) updateButtons() {
 	a.state.mu.RLock()
 	isRunning := a.state.IsRunning
+	isPaused := a.state.IsPaused
 	a.state.mu.RUnlock()
 	
 	a.validateBtn.Enable()
@@ -202,9 +206,17 @@ func (a *App) updateButtons() {
 	if isRunning {
 		a.runBtn.Disable()
 		a.stopBtn.Enable()
+		a.pauseBtn.Enable()
 	} else {
 		a.runBtn.Enable()
 		a.stopBtn.Disable()
+		a.pauseBtn.Disable()
+	}
+
+	if isPaused {
+		a.pauseBtn.SetText("▶️ 재개")
+	} else {
+		a.pauseBtn.SetText("⏸️ 일시정지")
 	}
 }
 
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"csvfire/internal/request"
+	"csvfire/internal/runner"
+)
+
+// canarySampleCount is the number of responses shown after the canary stage
+const canarySampleCount = 5
+
+// exitCodeCanaryStopped is the exit code of a run stopped after the canary stage
+const exitCodeCanaryStopped = 4
+
+// stage is a part of a run sent with a fixed concurrency
+type stage struct {
+	name        string
+	rows        int // 0 sends all remaining rows
+	concurrency int
+}
+
+// planStages splits a run into an optional canary stage and ramp stages.
+// The last stage sends the remaining rows at the final concurrency.
+func planStages(canary int, ramp []int, rampStep int, concurrency int) []stage {
+	var stages []stage
+
+	if canary > 0 {
+		canaryConcurrency := concurrency
+		if len(ramp) > 0 && ramp[0] < canaryConcurrency {
+			canaryConcurrency = ramp[0]
+		}
+		stages = append(stages, stage{name: "canary", rows: canary, concurrency: canaryConcurrency})
+	}
+
+	for i, rampConcurrency := range ramp {
+		rows := rampStep
+		if i == len(ramp)-1 {
+			rows = 0
+		}
+		stages = append(stages, stage{name: fmt.Sprintf("ramp %d", rampConcurrency), rows: rows, concurrency: rampConcurrency})
+	}
+
+	if len(ramp) == 0 {
+		stages = append(stages, stage{name: "main", concurrency: concurrency})
+	}
+
+	return stages
+}
+
+// parseRamp parses a concurrency ramp such as "1,4,8"
+func parseRamp(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var ramp []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("잘못된 동시성 단계 '%s'", part)
+		}
+		ramp = append(ramp, n)
+	}
+	return ramp, nil
+}
+
+// taskFeed splits the rows read from the CSV between the stages of a run
+type taskFeed struct {
+	src     <-chan runner.RowTask
+	pending []runner.RowTask // Taken from src by a stage that ended before sending them
+}
+
+// take forwards up to n tasks (all if n <= 0) until done is closed, then
+// closes the returned channel. A task taken but not delivered when done is
+// closed is kept for the next stage. finished is closed once the stage no
+// longer touches the feed.
+func (f *taskFeed) take(n int, done <-chan struct{}) (tasks <-chan runner.RowTask, finished <-chan struct{}) {
+	out := make(chan runner.RowTask)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer close(out)
+		for sent := 0; n <= 0 || sent < n; sent++ {
+			var task runner.RowTask
+			if len(f.pending) > 0 {
+				task = f.pending[0]
+				f.pending = f.pending[1:]
+			} else {
+				var ok bool
+				select {
+				case task, ok = <-f.src:
+					if !ok {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+
+			select {
+			case out <- task:
+			case <-done:
+				f.pending = append([]runner.RowTask{task}, f.pending...)
+				return
+			}
+		}
+	}()
+	return out, exited
+}
+
+// rest returns every task no stage sent, including the rows the CSV reader
+// has not read yet
+func (f *taskFeed) rest() <-chan runner.RowTask {
+	tasks, _ := f.take(0, nil)
+	return tasks
+}
+
+// continueCondition is an --auto-continue-if condition such as "success>=99%"
+type continueCondition struct {
+	metric    string
+	operator  string
+	threshold float64
+}
+
+var continueConditionPattern = regexp.MustCompile(`^\s*(success|failed)\s*(>=|<=|>|<)\s*([0-9]+(?:\.[0-9]+)?)\s*%?\s*$`)
+
+// parseContinueCondition parses an --auto-continue-if condition
+func parseContinueCondition(value string) (*continueCondition, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	match := continueConditionPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("잘못된 자동 진행 조건 '%s' (예: success>=99%%)", value)
+	}
+
+	threshold, _ := strconv.ParseFloat(match[3], 64)
+	return &continueCondition{metric: match[1], operator: match[2], threshold: threshold}, nil
+}
+
+// evaluate checks the condition against the canary result
+func (c *continueCondition) evaluate(result *runner.RunResult) (float64, bool) {
+	value, ok := successRate(result)
+	if !ok {
+		return 0, false
+	}
+	if c.metric == "failed" {
+		value = 100 - value
+	}
+
+	switch c.operator {
+	case ">=":
+		return value, value >= c.threshold
+	case "<=":
+		return value, value <= c.threshold
+	case ">":
+		return value, value > c.threshold
+	default:
+		return value, value < c.threshold
+	}
+}
+
+// String returns the condition as given on the command line
+func (c *continueCondition) String() string {
+	return fmt.Sprintf("%s%s%g%%", c.metric, c.operator, c.threshold)
+}
+
+// successRate returns the success percentage of the rows actually sent in a
+// stage; rows skipped by checkpoints don't count
+func successRate(result *runner.RunResult) (float64, bool) {
+	sent := result.SuccessRows + result.FailedRows
+	if sent == 0 {
+		return 0, false
+	}
+	return float64(result.SuccessRows) / float64(sent) * 100, true
+}
+
+// responseSample is a response shown after the canary stage
+type responseSample struct {
+	row    int
+	result *request.RequestResult
+}
+
+// sampleCollector keeps the first responses of a stage
+type sampleCollector struct {
+	mu      sync.Mutex
+	enabled bool
+	samples []responseSample
+}
+
+// add records a response while the collector is enabled
+func (s *sampleCollector) add(row int, result *request.RequestResult) {
+	if result == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.enabled && len(s.samples) < canarySampleCount {
+		s.samples = append(s.samples, responseSample{row: row, result: result})
+	}
+}
+
+// stop disables collection and returns the collected samples
+func (s *sampleCollector) stop() []responseSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enabled = false
+	return s.samples
+}
+
+// printCanarySummary prints the canary result and sample responses
+func printCanarySummary(result *runner.RunResult, samples []responseSample) {
+	fmt.Printf("\n=== 카나리 결과 ===\n")
+	fmt.Printf("처리: %d행 | 성공 %d | 실패 %d | 건너뜀 %d\n",
+		result.TotalRows, result.SuccessRows, result.FailedRows, result.SkippedRows)
+	if rate, ok := successRate(result); ok {
+		fmt.Printf("성공률: %.1f%% (건너뛴 행 제외)\n", rate)
+	}
+
+	if len(samples) > 0 {
+		fmt.Printf("\n응답 샘플:\n")
+		for _, sample := range samples {
+			status := "성공"
+			if !sample.result.Success {
+				status = "실패"
+				if sample.result.ErrorCategory != "" {
+					status += " (" + sample.result.ErrorCategory + ")"
+				}
+			}
+			preview := sample.result.ResponsePreview
+			if preview == "" {
+				preview = sample.result.ErrorDetail
+			}
+			if len([]rune(preview)) > 120 {
+				preview = string([]rune(preview)[:120]) + "..."
+			}
+			fmt.Printf("  행 %d: %s, 상태 %d, %dms | %s\n",
+				sample.row, status, sample.result.StatusCode, sample.result.LatencyMs, preview)
+		}
+	}
+}
+
+// stdinIsTerminal reports whether stdin can be a terminal; pipes and files
+// can't answer the canary prompt
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmContinue asks on stdin whether to continue after the canary stage.
+// An interrupt answers no; stdin closing without an answer is an error.
+func confirmContinue(interrupted <-chan struct{}) (bool, error) {
+	fmt.Printf("\n나머지 행을 계속 전송할까요? [y/N]: ")
+
+	// Reading stdin can't be cancelled, so an interrupted prompt leaves the
+	// reader blocked until the process exits
+	answers := make(chan string, 1)
+	go func() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			close(answers)
+			return
+		}
+		answers <- line
+	}()
+
+	select {
+	case line, ok := <-answers:
+		if !ok {
+			fmt.Println()
+			return false, fmt.Errorf("표준 입력이 닫혀 카나리 후 진행 여부를 확인할 수 없습니다 (비대화형 실행에는 --auto-continue-if 필요)")
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		return answer == "y" || answer == "yes", nil
+	case <-interrupted:
+		fmt.Println()
+		return false, nil
+	}
+}
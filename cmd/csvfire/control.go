@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"csvfire/internal/history"
+	"csvfire/internal/runner"
+)
+
+var controlSocket string
+
+// controlTimeout bounds a control command round trip
+const controlTimeout = 5 * time.Second
+
+// addControlFlags registers the flags for controlling a running run
+func addControlFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&controlSocket, "control", "", "실행 중 일시정지/재개와 동시성·속도 변경을 받을 유닛 소켓 경로")
+}
+
+// parseRateLimit parses a rate such as "5/s"; an empty value or "0" means unlimited
+func parseRateLimit(value string) (float64, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	if !strings.HasSuffix(value, "/s") {
+		return 0, fmt.Errorf("레이트 리밋 형식이 잘못됨 (예: 5/s)")
+	}
+	rps, err := strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("레이트 리밋 파싱 실패: %w", err)
+	}
+	if rps < 0 {
+		return 0, fmt.Errorf("레이트 리밋은 0 이상이어야 합니다")
+	}
+	return rps, nil
+}
+
+// runController applies control commands received by signal or control
+// socket to a running Runner
+type runController struct {
+	runner   *runner.Runner
+	logDir   string
+	listener net.Listener
+}
+
+// startControl starts listening for control signals and, if socketPath is
+// set, control socket connections. It returns a function that stops listening.
+func startControl(r *runner.Runner, logDir, socketPath string) (func(), error) {
+	c := &runController{runner: r, logDir: logDir}
+
+	if socketPath != "" {
+		// A socket left behind by a run that was killed would block Listen
+		if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("제어 소켓이 이미 사용 중입니다: %s", socketPath)
+		}
+		os.Remove(socketPath)
+
+		listener, err := net.Listen("unix", socketPath)
+		if err != nil {
+			return nil, fmt.Errorf("제어 소켓 생성 실패: %w", err)
+		}
+		c.listener = listener
+		go c.serve()
+	}
+
+	stopSignals := watchControlSignals(c)
+
+	return func() {
+		stopSignals()
+		if c.listener != nil {
+			c.listener.Close()
+			os.Remove(socketPath)
+		}
+	}, nil
+}
+
+// serve accepts control socket connections until the listener is closed
+func (c *runController) serve() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return
+		}
+		go c.handle(conn)
+	}
+}
+
+// handle answers the commands of one control connection, one line each
+func (c *runController) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		message, err := c.apply(strings.Fields(line))
+		if err != nil {
+			fmt.Fprintf(conn, "error %s\n", err)
+		} else {
+			fmt.Fprintf(conn, "ok %s\n", message)
+		}
+	}
+}
+
+// apply executes a control command and returns a message describing the result
+func (c *runController) apply(args []string) (string, error) {
+	switch args[0] {
+	case "pause":
+		return c.pause()
+	case "resume":
+		return c.resume()
+	case "status":
+		return describeControlStatus(c.runner.Progress()), nil
+	case "concurrency":
+		if len(args) != 2 {
+			return "", fmt.Errorf("사용법: concurrency <동시 요청 수>")
+		}
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value < 1 {
+			return "", fmt.Errorf("동시성은 1 이상의 정수여야 합니다: %s", args[1])
+		}
+		c.runner.SetConcurrency(value)
+		c.announce(fmt.Sprintf("동시성 변경: %d", value))
+		return fmt.Sprintf("동시성 %d", value), nil
+	case "rate":
+		if len(args) != 2 {
+			return "", fmt.Errorf("사용법: rate <속도, 예: 5/s, 0은 제한 없음>")
+		}
+		value, err := parseRateLimit(args[1])
+		if err != nil {
+			return "", err
+		}
+		c.runner.SetRateLimit(value)
+		c.announce(fmt.Sprintf("속도 변경: %s", formatRate(value)))
+		return fmt.Sprintf("속도 %s", formatRate(value)), nil
+	default:
+		return "", fmt.Errorf("알 수 없는 명령: %s (pause, resume, status, concurrency, rate)", args[0])
+	}
+}
+
+// pause stops dispatch and saves checkpoints, since a pause may last long
+func (c *runController) pause() (string, error) {
+	if !c.runner.Pause() {
+		return "", fmt.Errorf("이미 일시정지 상태입니다")
+	}
+	if err := history.SaveCheckpoints(c.logDir, c.runner.GetProcessedHashes()); err != nil {
+		fmt.Printf("체크포인트 저장 오류: %v\n", err)
+	}
+	c.announce("일시정지: 진행 중인 요청만 마무리하고 새 행은 보내지 않습니다")
+	return "일시정지", nil
+}
+
+// resume continues dispatch after pause
+func (c *runController) resume() (string, error) {
+	if !c.runner.Resume() {
+		return "", fmt.Errorf("일시정지 상태가 아닙니다")
+	}
+	c.announce("재개: 남은 행을 이어서 보냅니다")
+	return "재개", nil
+}
+
+// announce prints a control event on its own line
+func (c *runController) announce(message string) {
+	fmt.Printf("\n%s\n", message)
+}
+
+// describeControlStatus formats the progress for the status command
+func describeControlStatus(progress runner.Progress) string {
+	state := "실행 중"
+	if progress.Paused {
+		state = "일시정지"
+	}
+	return fmt.Sprintf("%s | 처리 %d | 성공 %d | 실패 %d | 건너뜀 %d | 동시성 %d | 속도 %s",
+		state, processedRows(progress), progress.SuccessRows, progress.FailedRows, progress.SkippedRows,
+		progress.Concurrency, formatRate(progress.RateLimit))
+}
+
+// formatRate formats a requests-per-second rate (0 = unlimited)
+func formatRate(rps float64) string {
+	if rps <= 0 {
+		return "제한 없음"
+	}
+	return strconv.FormatFloat(rps, 'g', -1, 64) + "/s"
+}
+
+// runControl sends a command to the control socket of a running run
+func runControl(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	conn, err := net.DialTimeout("unix", controlSocket, controlTimeout)
+	if err != nil {
+		return fmt.Errorf("제어 소켓 연결 실패: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	if _, err := fmt.Fprintf(conn, "%s\n", strings.Join(args, " ")); err != nil {
+		return fmt.Errorf("명령 전송 실패: %w", err)
+	}
+
+	response, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("응답 읽기 실패: %w", err)
+	}
+
+	status, message, _ := strings.Cut(strings.TrimSpace(response), " ")
+	if status != "ok" {
+		return errors.New(message)
+	}
+	fmt.Println(message)
+	return nil
+}
@@ -0,0 +1,7 @@
+package main
+
+// watchControlSignals is a no-op on Windows, which has no SIGUSR1/SIGUSR2;
+// use the control socket instead
+func watchControlSignals(c *runController) func() {
+	return func() {}
+}
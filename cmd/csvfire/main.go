@@ -4,39 +4,71 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"csvfire/internal/cassette"
 	"csvfire/internal/config"
+	"csvfire/internal/history"
 	"csvfire/internal/logger"
+	"csvfire/internal/metrics"
+	"csvfire/internal/mock"
 	"csvfire/internal/reader"
+	"csvfire/internal/report"
 	"csvfire/internal/request"
 	"csvfire/internal/runner"
+	"csvfire/internal/tracing"
 	"csvfire/internal/validator"
 )
 
 var (
-	schemaFile    string
-	csvFile       string
-	requestFile   string
-	reportFile    string
-	logDir        string
-	exportFailed  string
-	concurrency   int
-	rateLimit     string
-	timeoutStr    string
-	strict        bool
-	resume        bool
-	limit         int
-	previewFile   string
+	schemaFile       string
+	csvFile          string
+	requestFile      string
+	reportFile       string
+	logDir           string
+	exportFailed     string
+	concurrency      int
+	rateLimit        string
+	timeoutStr       string
+	strict           bool
+	resume           bool
+	limit            int
+	previewFile      string
+	runDir           string
+	reportOut        string
+	failedFile       string
+	metricsAddr      string
+	verbose          bool
+	traceOTLP        string
+	traceInsecure    bool
+	traceFile        string
+	dryRun           bool
+	mockFile         string
+	recordFile       string
+	replayFile       string
+	redactHeaders    []string
+	exportNormalized bool
+	orderedOutput    bool
+	reorderWindow    int
+	canaryRows       int
+	autoContinueIf   string
+	rampSpec         string
+	rampStep         int
+	retryFrom        string
+	retryCategories  []string
+	retryStatuses    string
+	retryConcurrency int
+	retryTimeout     string
+	mergeOut         string
+	mergeFailed      []string
 )
 
 func main() {
@@ -58,6 +90,7 @@ func main() {
 	validateCmd.Flags().StringVar(&csvFile, "csv", "", "CSV 파일 경로")
 	validateCmd.Flags().StringVar(&reportFile, "report", "logs/validate_errors.csv", "검증 오류 리포트 파일")
 	validateCmd.Flags().BoolVar(&strict, "strict", false, "검증 실패 시 종료 코드 1로 종료")
+	addSelectionFlags(validateCmd)
 	validateCmd.MarkFlagRequired("schema")
 	validateCmd.MarkFlagRequired("csv")
 
@@ -72,8 +105,9 @@ func main() {
 	renderCmd.Flags().StringVar(&schemaFile, "schema", "", "스키마 파일 경로")
 	renderCmd.Flags().StringVar(&csvFile, "csv", "", "CSV 파일 경로")
 	renderCmd.Flags().StringVar(&requestFile, "request", "", "요청 설정 파일 경로")
-	renderCmd.Flags().IntVar(&limit, "limit", 10, "미리보기할 행 수")
+	renderCmd.Flags().IntVar(&limit, "limit", 10, "미리보기할 행 수 (0이면 선택한 모든 행)")
 	renderCmd.Flags().StringVar(&previewFile, "preview", "logs/preview.jsonl", "미리보기 파일 경로")
+	addSelectionFlags(renderCmd)
 	renderCmd.MarkFlagRequired("schema")
 	renderCmd.MarkFlagRequired("csv")
 	renderCmd.MarkFlagRequired("request")
@@ -94,19 +128,131 @@ func main() {
 	runCmd.Flags().StringVar(&timeoutStr, "timeout", "10s", "요청 타임아웃")
 	runCmd.Flags().StringVar(&logDir, "log", "logs", "로그 디렉토리")
 	runCmd.Flags().StringVar(&exportFailed, "export-failed", "", "실패한 행을 내보낼 파일")
+	runCmd.Flags().BoolVar(&exportNormalized, "export-normalized", false, "실패한 행 내보내기에 정규화된 값 컬럼 추가")
 	runCmd.Flags().BoolVar(&resume, "resume", false, "이전 실행 재시작")
+	runCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Prometheus 메트릭 HTTP 주소 (예: :9090)")
+	runCmd.Flags().BoolVar(&verbose, "verbose", false, "진행 표시 대신 행별 결과 출력")
+	runCmd.Flags().StringVar(&traceOTLP, "trace-otlp", "", "OpenTelemetry OTLP/HTTP 수집기 주소 (예: localhost:4318)")
+	runCmd.Flags().BoolVar(&traceInsecure, "trace-insecure", false, "OTLP 전송 시 TLS 미사용")
+	runCmd.Flags().StringVar(&traceFile, "trace-file", "", "트레이스 스팬을 JSON으로 기록할 파일")
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "실제 API 대신 내장 모의 서버로 전체 파이프라인 실행")
+	runCmd.Flags().StringVar(&mockFile, "mock", "", "드라이런 모의 서버 응답 스크립트 (mock.yaml)")
+	runCmd.Flags().StringVar(&recordFile, "record", "", "요청/응답을 기록할 카세트 파일")
+	runCmd.Flags().StringVar(&replayFile, "replay", "", "기록된 카세트로 응답 재생 (네트워크 미사용)")
+	runCmd.Flags().StringSliceVar(&redactHeaders, "redact-header", nil, "카세트에 값을 남기지 않을 헤더 (예: X-Tenant-Secret)")
+	runCmd.Flags().BoolVar(&orderedOutput, "ordered-output", false, "로그와 내보내기를 입력 행 순서대로 기록")
+	runCmd.Flags().IntVar(&reorderWindow, "reorder-window", runner.DefaultReorderWindow, "순서 유지 모드에서 처리 중이거나 대기할 수 있는 최대 행 수")
+	runCmd.Flags().IntVar(&canaryRows, "canary", 0, "먼저 N행만 전송하고 결과 확인 후 나머지 진행")
+	runCmd.Flags().StringVar(&autoContinueIf, "auto-continue-if", "", "카나리 결과가 조건을 만족하면 확인 없이 진행 (예: success>=99%)")
+	runCmd.Flags().StringVar(&rampSpec, "ramp", "", "단계별로 늘려갈 동시성 (예: 1,4,8)")
+	runCmd.Flags().IntVar(&rampStep, "ramp-step", 100, "동시성 단계별 전송 행 수 (마지막 단계는 나머지 전체)")
+	addSelectionFlags(runCmd)
+	addShardFlags(runCmd)
+	addControlFlags(runCmd)
+	addShutdownFlags(runCmd)
 	runCmd.MarkFlagRequired("schema")
 	runCmd.MarkFlagRequired("csv")
 	runCmd.MarkFlagRequired("request")
 
-	rootCmd.AddCommand(validateCmd, renderCmd, runCmd)
+	// report 서브커맨드
+	var reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "실행 결과 리포트 생성",
+		Long:  "실행 로그 디렉토리로부터 HTML/Markdown 요약 리포트를 생성합니다",
+		RunE:  runReport,
+	}
+
+	reportCmd.Flags().StringVar(&runDir, "run", "", "실행 로그 디렉토리")
+	reportCmd.Flags().StringVar(&reportOut, "out", "", "리포트 출력 디렉토리 (기본값: 실행 로그 디렉토리)")
+	reportCmd.Flags().StringVar(&failedFile, "failed", "", "리포트에 링크할 실패한 행 파일")
+	reportCmd.MarkFlagRequired("run")
+
+	// retry-failed 서브커맨드
+	var retryCmd = &cobra.Command{
+		Use:   "retry-failed",
+		Short: "실패한 행 재실행",
+		Long:  "실행 로그 디렉토리의 매니페스트에 저장된 원래 설정으로 실패한 행만 다시 실행하고 같은 실행 이력에 결과를 추가합니다",
+		RunE:  runRetryFailed,
+	}
+
+	retryCmd.Flags().StringVar(&retryFrom, "from", "", "실행 로그 디렉토리")
+	retryCmd.Flags().StringSliceVar(&retryCategories, "category", nil, "재시도할 오류 분류 (예: timeout,connection_refused)")
+	retryCmd.Flags().StringVar(&retryStatuses, "status", "", "재시도할 상태 코드 (예: 5xx,429,500-504)")
+	retryCmd.Flags().IntVar(&retryConcurrency, "concurrency", 0, "동시 요청 수 (기본값: 원래 실행 설정)")
+	retryCmd.Flags().StringVar(&rateLimit, "rate", "", "요청 속도 제한 (기본값: 원래 실행 설정)")
+	retryCmd.Flags().StringVar(&retryTimeout, "timeout", "", "요청 타임아웃 (기본값: 원래 실행 설정)")
+	retryCmd.Flags().StringVar(&exportFailed, "export-failed", "", "여전히 실패한 행을 내보낼 파일")
+	retryCmd.Flags().BoolVar(&exportNormalized, "export-normalized", false, "실패한 행 내보내기에 정규화된 값 컬럼 추가")
+	retryCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Prometheus 메트릭 HTTP 주소 (예: :9090)")
+	retryCmd.Flags().BoolVar(&verbose, "verbose", false, "진행 표시 대신 행별 결과 출력")
+	retryCmd.Flags().BoolVar(&orderedOutput, "ordered-output", false, "로그와 내보내기를 입력 행 순서대로 기록")
+	retryCmd.Flags().IntVar(&reorderWindow, "reorder-window", runner.DefaultReorderWindow, "순서 유지 모드에서 처리 중이거나 대기할 수 있는 최대 행 수")
+	addControlFlags(retryCmd)
+	addShutdownFlags(retryCmd)
+	retryCmd.MarkFlagRequired("from")
+
+	// merge-runs 서브커맨드
+	var mergeCmd = &cobra.Command{
+		Use:   "merge-runs [실행 로그 디렉토리...]",
+		Short: "샤드 실행 결과 병합",
+		Long:  "--shard로 나눠 실행한 로그 디렉토리들의 로그, 체크포인트, 실행 이력과 실패한 행 파일을 하나로 합칩니다",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runMergeRuns,
+	}
+
+	mergeCmd.Flags().StringVar(&mergeOut, "out", "", "병합 결과 로그 디렉토리")
+	mergeCmd.Flags().StringSliceVar(&mergeFailed, "failed", nil, "병합할 샤드별 실패한 행 파일")
+	mergeCmd.Flags().StringVar(&exportFailed, "export-failed", "", "병합한 실패한 행을 기록할 파일")
+	mergeCmd.MarkFlagRequired("out")
+
+	// control 서브커맨드
+	var controlCmd = &cobra.Command{
+		Use:   "control [pause|resume|status|concurrency N|rate 5/s]",
+		Short: "실행 중인 run 제어",
+		Long:  "--control 소켓으로 실행 중인 run을 일시정지, 재개하거나 동시성과 속도를 바꿉니다",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runControl,
+	}
+
+	controlCmd.Flags().StringVar(&controlSocket, "socket", "", "run의 --control 소켓 경로")
+	controlCmd.MarkFlagRequired("socket")
+
+	// serve 서브커맨드
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "작업 API 서버 실행",
+		Long:  "HTTP/JSON API로 작업을 업로드, 시작, 일시정지, 재개, 취소하고 진행 상황과 로그를 조회하는 서버를 실행합니다",
+		RunE:  runServe,
+	}
+
+	addServeFlags(serveCmd)
+	addShutdownFlags(serveCmd)
+
+	rootCmd.AddCommand(validateCmd, renderCmd, runCmd, reportCmd, retryCmd, mergeCmd, controlCmd, serveCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "오류: %v\n", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeAborted is the exit code of a run stopped by the circuit breaker
+const exitCodeAborted = 3
+
+// exitError ends the process with a specific exit code once cleanup has run
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e *exitError) Error() string {
+	return e.message
+}
+
 func runValidate(cmd *cobra.Command, args []string) error {
 	// 스키마 로드
 	schema, err := config.LoadSchema(schemaFile)
@@ -131,27 +277,31 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	fmt.Printf("CSV 검증을 시작합니다: %s\n", csvFile)
 	fmt.Printf("스키마: %s\n", schemaFile)
 
-	// 미리보기로 모든 행 읽기
-	rows, err := csvReader.GetPreviewRows(1000000) // 충분히 큰 수로 모든 행 읽기
+	// 행 선택
+	selection, err := buildSelection(cmd, schema)
 	if err != nil {
-		return fmt.Errorf("CSV 읽기 실패: %w", err)
+		return err
 	}
+	csvReader.SetSelection(selection)
 
-	totalRows := len(rows)
-	validRows := 0
-	errorCount := 0
-
-	// 검증 오류 수집
+	// 검증 오류 수집 (행 번호는 원본 파일 기준)
 	var allErrors []validator.ValidationError
 
-	for i, row := range rows {
-		result := val.ValidateRow(i+1, row)
+	totalRows, validRows, errorCount, err := csvReader.ValidateRowsStream(func(rowNum int, data map[string]string) (bool, []error) {
+		result := val.ValidateRow(rowNum, data)
 		if result.Valid {
-			validRows++
-		} else {
-			errorCount += len(result.Errors)
-			allErrors = append(allErrors, result.Errors...)
+			return true, nil
+		}
+
+		allErrors = append(allErrors, result.Errors...)
+		errs := make([]error, len(result.Errors))
+		for i := range result.Errors {
+			errs[i] = fmt.Errorf("%s", result.Errors[i].Message)
 		}
+		return false, errs
+	})
+	if err != nil {
+		return fmt.Errorf("CSV 읽기 실패: %w", err)
 	}
 
 	// 리포트 작성
@@ -211,10 +361,23 @@ func runRender(cmd *cobra.Command, args []string) error {
 	val := validator.NewValidator(schema)
 
 	fmt.Printf("요청 템플릿 미리보기를 생성합니다\n")
-	fmt.Printf("제한: %d행\n", limit)
+	if limit > 0 {
+		fmt.Printf("제한: %d행\n", limit)
+	}
 
-	// 미리보기 행 읽기
-	rows, err := csvReader.GetPreviewRows(limit)
+	// 행 선택
+	selection, err := buildSelection(cmd, schema)
+	if err != nil {
+		return err
+	}
+	csvReader.SetSelection(selection)
+
+	// 미리보기 행 읽기 (행 번호는 원본 파일 기준)
+	var rows []runner.RowTask
+	err = csvReader.ForEachRow(func(task runner.RowTask) bool {
+		rows = append(rows, task)
+		return limit <= 0 || len(rows) < limit
+	})
 	if err != nil {
 		return fmt.Errorf("CSV 읽기 실패: %w", err)
 	}
@@ -233,24 +396,24 @@ func runRender(cmd *cobra.Command, args []string) error {
 	defer file.Close()
 
 	processedCount := 0
-	for i, row := range rows {
+	for _, row := range rows {
 		// 검증
-		result := val.ValidateRow(i+1, row)
+		result := val.ValidateRow(row.RowNumber, row.Data)
 		if !result.Valid {
-			fmt.Printf("행 %d: 검증 실패 (건너뛰기)\n", i+1)
+			fmt.Printf("행 %d: 검증 실패 (건너뛰기)\n", row.RowNumber)
 			continue
 		}
 
 		// 템플릿 렌더링
-		requestData, err := renderer.Render(result.Data)
+		requestData, err := renderer.Render(result)
 		if err != nil {
-			fmt.Printf("행 %d: 템플릿 렌더링 실패: %v\n", i+1, err)
+			fmt.Printf("행 %d: 템플릿 렌더링 실패: %v\n", row.RowNumber, err)
 			continue
 		}
 
 		// JSON으로 직렬화
 		jsonData, err := json.Marshal(map[string]interface{}{
-			"row":     i + 1,
+			"row":     row.RowNumber,
 			"method":  requestData.Method,
 			"url":     requestData.URL,
 			"headers": requestData.Headers,
@@ -258,7 +421,7 @@ func runRender(cmd *cobra.Command, args []string) error {
 			"proxy":   requestData.Proxy,
 		})
 		if err != nil {
-			fmt.Printf("행 %d: JSON 직렬화 실패: %v\n", i+1, err)
+			fmt.Printf("행 %d: JSON 직렬화 실패: %v\n", row.RowNumber, err)
 			continue
 		}
 
@@ -267,7 +430,7 @@ func runRender(cmd *cobra.Command, args []string) error {
 		file.Write([]byte("\n"))
 		processedCount++
 
-		fmt.Printf("행 %d: 렌더링 완료\n", i+1)
+		fmt.Printf("행 %d: 렌더링 완료\n", row.RowNumber)
 	}
 
 	fmt.Printf("\n미리보기 완료: %d행 처리됨\n", processedCount)
@@ -288,6 +451,186 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("요청 설정 로드 실패: %w", err)
 	}
 
+	// 재시도에 사용할 설정과 옵션을 실행 매니페스트로 보관
+	manifest, err := history.NewManifest(schemaFile, requestFile, csvFile, history.Options{
+		Concurrency: concurrency,
+		RateLimit:   rateLimit,
+		Timeout:     timeoutStr,
+		DryRun:      dryRun,
+		MockFile:    mockFile,
+	})
+	if err != nil {
+		return fmt.Errorf("실행 매니페스트 생성 실패: %w", err)
+	}
+
+	// 행 선택 (--rows, --where, --sample)
+	selection, err := buildSelection(cmd, schema)
+	if err != nil {
+		return err
+	}
+
+	manifest.Options.Shard = selection.Shard()
+	manifest.Options.ShardKey = shardKey
+
+	plan := &runPlan{
+		manifest:   manifest,
+		command:    "run",
+		filter:     selection.String(),
+		selection:  selection,
+		appendLogs: resume,
+	}
+
+	// 재시작 시 이전 실행 이력을 유지하고, 같은 CSV라면 이미 기록된 행은 건너뜀
+	// (실패한 행은 retry-failed로 재시도, 중단으로 보내지 않은 행은 다시 전송)
+	if resume {
+		if previous, err := history.LoadManifest(logDir); err == nil {
+			// 드라이런의 모의 전송 기록을 실제 전송으로 이어가지 않음 (반대도 마찬가지)
+			if previous.Options.DryRun != dryRun {
+				if previous.Options.DryRun {
+					return fmt.Errorf("%s의 이전 실행은 드라이런이어서 실제 실행으로 재시작할 수 없습니다: --resume 없이 실행하거나 다른 --log 디렉토리를 사용하세요", logDir)
+				}
+				return fmt.Errorf("%s의 이전 실행은 실제 실행이어서 드라이런으로 재시작할 수 없습니다: 다른 --log 디렉토리를 사용하세요", logDir)
+			}
+			manifest.Attempts = previous.Attempts
+			if previous.CSVHash == manifest.CSVHash {
+				outcomes, err := history.ReadOutcomes(logDir)
+				if err != nil {
+					return fmt.Errorf("실행 로그 읽기 실패: %w", err)
+				}
+				plan.skipRows = make(map[int]bool, len(outcomes))
+				for row, outcome := range outcomes {
+					if outcome.Attempted() {
+						plan.skipRows[row] = true
+					}
+				}
+				if len(plan.skipRows) > 0 {
+					fmt.Printf("재시작: 이미 기록된 %d행 이후부터 이어서 실행합니다\n", len(plan.skipRows))
+				}
+			}
+		}
+	}
+
+	cmd.SilenceUsage = true
+	return executeRun(schema, requestConfig, plan)
+}
+
+func runRetryFailed(cmd *cobra.Command, args []string) error {
+	manifest, err := history.LoadManifest(retryFrom)
+	if err != nil {
+		return fmt.Errorf("실행 매니페스트 로드 실패: %w", err)
+	}
+
+	// 행은 원본 CSV의 위치로 식별되므로 파일이 바뀌었으면 중단
+	if err := manifest.VerifyCSV(); err != nil {
+		return fmt.Errorf("원본 CSV 확인 실패: %w", err)
+	}
+
+	schema, requestConfig, err := manifest.LoadConfigs()
+	if err != nil {
+		return fmt.Errorf("저장된 설정 로드 실패: %w", err)
+	}
+
+	filter, err := history.ParseFilter(retryCategories, retryStatuses)
+	if err != nil {
+		return fmt.Errorf("재시도 조건 파싱 실패: %w", err)
+	}
+
+	outcomes, err := history.ReadOutcomes(retryFrom)
+	if err != nil {
+		return fmt.Errorf("실행 로그 읽기 실패: %w", err)
+	}
+
+	rows := history.SelectRows(outcomes, filter)
+	if len(rows) == 0 {
+		fmt.Printf("재시도할 실패 행이 없습니다\n")
+		return nil
+	}
+
+	// 원래 실행 옵션 사용 (명시한 플래그는 우선)
+	csvFile = manifest.CSVFile
+	logDir = retryFrom
+	concurrency = manifest.Options.Concurrency
+	if cmd.Flags().Changed("concurrency") {
+		concurrency = retryConcurrency
+	}
+	if !cmd.Flags().Changed("rate") {
+		rateLimit = manifest.Options.RateLimit
+	}
+	timeoutStr = manifest.Options.Timeout
+	if cmd.Flags().Changed("timeout") {
+		timeoutStr = retryTimeout
+	}
+	dryRun = manifest.Options.DryRun
+	mockFile = manifest.Options.MockFile
+
+	fmt.Printf("실패한 행을 재시도합니다: %s\n", retryFrom)
+	if filter.String() != "" {
+		fmt.Printf("조건: %s\n", filter.String())
+	}
+	fmt.Printf("재시도 대상: %d행\n", len(rows))
+
+	cmd.SilenceUsage = true
+	return executeRun(schema, requestConfig, &runPlan{
+		manifest:   manifest,
+		command:    "retry-failed",
+		filter:     filter.String(),
+		rows:       rows,
+		appendLogs: true,
+	})
+}
+
+func runMergeRuns(cmd *cobra.Command, args []string) error {
+	if len(mergeFailed) > 0 && exportFailed == "" {
+		return fmt.Errorf("--failed는 --export-failed와 함께 사용해야 합니다")
+	}
+
+	cmd.SilenceUsage = true
+
+	result, err := history.MergeRuns(mergeOut, args)
+	if err != nil {
+		return fmt.Errorf("실행 결과 병합 실패: %w", err)
+	}
+
+	fmt.Printf("병합 완료: %s (%d개 실행)\n", mergeOut, result.Runs)
+	if len(result.Shards) > 0 {
+		fmt.Printf("샤드: %s\n", strings.Join(result.Shards, ", "))
+	}
+	if len(result.MissingShards) > 0 {
+		fmt.Printf("경고: 누락된 샤드 %s\n", strings.Join(result.MissingShards, ", "))
+	}
+	for _, name := range history.MergedLogFiles {
+		if rows, ok := result.Rows[name]; ok {
+			fmt.Printf("%s: %d행\n", name, rows)
+		}
+	}
+	fmt.Printf("체크포인트: %d건\n", result.Checkpoints)
+
+	if exportFailed != "" {
+		rows, err := history.MergeFailedRows(exportFailed, mergeFailed)
+		if err != nil {
+			return fmt.Errorf("실패한 행 파일 병합 실패: %w", err)
+		}
+		fmt.Printf("실패한 행 병합: %s (%d행)\n", exportFailed, rows)
+	}
+
+	return nil
+}
+
+// runPlan describes which rows an execution sends and how it is recorded in the run history
+type runPlan struct {
+	manifest   *history.Manifest
+	command    string
+	filter     string
+	rows       map[int]bool      // Rows to send (nil for every row)
+	selection  *reader.Selection // Rows picked by --rows, --where and --sample (nil for every row)
+	skipRows   map[int]bool      // Rows already logged by an earlier attempt
+	appendLogs bool              // Append to existing logs and checkpoints instead of replacing them
+}
+
+// executeRun sends the rows of csvFile and writes logs and the manifest to logDir
+func executeRun(schema *config.Schema, requestConfig *config.RequestConfig, plan *runPlan) error {
+	var err error
+
 	// 타임아웃 파싱
 	timeout, err := time.ParseDuration(timeoutStr)
 	if err != nil {
@@ -295,16 +638,57 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	}
 
 	// 레이트 리밋 파싱
-	var rateLimitValue float64
-	if rateLimit != "" {
-		if strings.HasSuffix(rateLimit, "/s") {
-			rateStr := strings.TrimSuffix(rateLimit, "/s")
-			rateLimitValue, err = strconv.ParseFloat(rateStr, 64)
-			if err != nil {
-				return fmt.Errorf("레이트 리밋 파싱 실패: %w", err)
-			}
-		} else {
-			return fmt.Errorf("레이트 리밋 형식이 잘못됨 (예: 5/s)")
+	rateLimitValue, err := parseRateLimit(rateLimit)
+	if err != nil {
+		return err
+	}
+
+	// 카나리/단계별 동시성 파싱
+	ramp, err := parseRamp(rampSpec)
+	if err != nil {
+		return err
+	}
+	continueIf, err := parseContinueCondition(autoContinueIf)
+	if err != nil {
+		return err
+	}
+	if continueIf != nil && canaryRows <= 0 {
+		return fmt.Errorf("--auto-continue-if는 --canary와 함께 사용해야 합니다")
+	}
+	if canaryRows > 0 && continueIf == nil && !stdinIsTerminal() {
+		return fmt.Errorf("표준 입력이 터미널이 아니어서 카나리 후 진행 여부를 물을 수 없습니다: --auto-continue-if를 지정하세요")
+	}
+	if rampStep <= 0 {
+		return fmt.Errorf("--ramp-step은 1 이상이어야 합니다")
+	}
+
+	// 컨텍스트 설정 (Ctrl+C 처리)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 트레이싱 설정
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		OTLPEndpoint: traceOTLP,
+		OTLPInsecure: traceInsecure,
+		File:         traceFile,
+	})
+	if err != nil {
+		return fmt.Errorf("트레이싱 설정 실패: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			fmt.Printf("트레이스 내보내기 오류: %v\n", err)
+		}
+	}()
+
+	// 메트릭 설정
+	var metricsInstance *metrics.Metrics
+	if metricsAddr != "" {
+		metricsInstance = metrics.NewMetrics()
+		if err := metricsInstance.Serve(ctx, metricsAddr); err != nil {
+			return fmt.Errorf("메트릭 서버 시작 실패: %w", err)
 		}
 	}
 
@@ -314,6 +698,51 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		RateLimit:   rateLimitValue,
 		Timeout:     timeout,
 		Resume:      resume,
+		Metrics:     metricsInstance,
+
+		OrderedOutput: orderedOutput,
+		ReorderWindow: reorderWindow,
+	}
+
+	// 드라이런 모의 서버 설정
+	var mockServer *mock.Server
+	if dryRun {
+		mockConfig, err := loadMockConfig(requestConfig)
+		if err != nil {
+			return fmt.Errorf("모의 서버 설정 로드 실패: %w", err)
+		}
+		mockServer = mock.NewServer(mockConfig)
+		if err := mockServer.Start(); err != nil {
+			return err
+		}
+		defer mockServer.Close()
+		runConfig.Transport = mockServer.Transport()
+	} else if mockFile != "" {
+		return fmt.Errorf("--mock은 --dry-run과 함께 사용해야 합니다")
+	}
+
+	// 카세트 기록/재생 설정
+	if replayFile != "" && (recordFile != "" || dryRun) {
+		return fmt.Errorf("--replay는 --record 또는 --dry-run과 함께 사용할 수 없습니다")
+	}
+
+	var recorder *cassette.Recorder
+	if recordFile != "" {
+		recorder, err = cassette.NewRecorder(recordFile, redactHeaders)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		runConfig.WrapTransport = recorder.Wrap
+	}
+
+	var replayer *cassette.Replayer
+	if replayFile != "" {
+		replayer, err = cassette.NewReplayer(replayFile)
+		if err != nil {
+			return err
+		}
+		runConfig.Transport = replayer.Transport()
 	}
 
 	// 런너 생성
@@ -322,50 +751,129 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("런너 생성 실패: %w", err)
 	}
 
-	// 로거 생성
-	loggerInstance, err := logger.NewLogger(schema, logDir)
+	// 로거 생성 (재시도는 기존 로그에 이어서 기록)
+	var loggerInstance *logger.Logger
+	if plan.appendLogs {
+		loggerInstance, err = logger.NewAppendLogger(schema, logDir)
+	} else {
+		loggerInstance, err = logger.NewLogger(schema, logDir)
+	}
 	if err != nil {
 		return fmt.Errorf("로거 생성 실패: %w", err)
 	}
 	defer loggerInstance.Close()
 
+	if err := plan.manifest.Save(logDir); err != nil {
+		return fmt.Errorf("실행 매니페스트 저장 실패: %w", err)
+	}
+
+	// 이전에 성공한 요청은 다시 보내지 않음
+	if plan.appendLogs {
+		checkpoints, err := history.LoadCheckpoints(logDir)
+		if err != nil {
+			return fmt.Errorf("체크포인트 로드 실패: %w", err)
+		}
+		runnerInstance.LoadCheckpoints(checkpoints)
+		if len(checkpoints) > 0 {
+			fmt.Printf("체크포인트: 이미 성공한 요청 %d건 건너뜀\n", len(checkpoints))
+		}
+	}
+
+	// 실패한 행은 실행 중에 파일로 바로 기록
+	if exportFailed != "" {
+		if err := loggerInstance.StartFailedRowExport(exportFailed, exportNormalized); err != nil {
+			return fmt.Errorf("실패한 행 파일 생성 실패: %w", err)
+		}
+	}
+
 	// CSV 리더 생성
 	csvReader := reader.NewCSVReader(schema, csvFile)
+	if plan.rows != nil || len(plan.skipRows) > 0 {
+		csvReader.SetRowFilter(func(rowNumber int) bool {
+			if plan.rows != nil && !plan.rows[rowNumber] {
+				return false
+			}
+			return !plan.skipRows[rowNumber]
+		})
+	}
+	csvReader.SetSelection(plan.selection)
 
+	if mockServer != nil {
+		fmt.Printf("드라이런 모드: 모든 요청을 모의 서버(%s)로 전송합니다\n", mockServer.Addr())
+	}
+	if recorder != nil {
+		fmt.Printf("기록 모드: %s\n", recordFile)
+	}
+	if replayer != nil {
+		fmt.Printf("재생 모드: %s (기록된 요청 %d개)\n", replayFile, replayer.Size())
+	}
 	fmt.Printf("API 호출 실행을 시작합니다\n")
 	fmt.Printf("동시성: %d\n", concurrency)
 	if rateLimitValue > 0 {
 		fmt.Printf("레이트 리밋: %.1f/s\n", rateLimitValue)
 	}
 	fmt.Printf("타임아웃: %v\n", timeout)
+	if requestConfig.Schedule != nil {
+		fmt.Printf("허용 시간대: %s\n", describeSchedule(requestConfig.Schedule))
+	}
+	if metricsAddr != "" {
+		fmt.Printf("메트릭: http://%s/metrics\n", metricsAddr)
+	}
 
-	// 컨텍스트 설정 (Ctrl+C 처리)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// 첫 중단 신호는 전송만 멈추고 진행 중인 요청을 기다림, 두 번째 신호나 대기 시간 초과 시 취소
+	interrupted := make(chan struct{})
+	stopWatchingInterrupt := watchInterrupt(func() {
+		close(interrupted)
+		runnerInstance.Stop()
+	}, cancel)
+	defer stopWatchingInterrupt()
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		fmt.Printf("\n중단 신호 수신, 정리 중...\n")
-		cancel()
-	}()
+	// 일시정지/재개 (SIGUSR1/SIGUSR2) 및 제어 소켓
+	stopControl, err := startControl(runnerInstance, logDir, controlSocket)
+	if err != nil {
+		return err
+	}
+	defer stopControl()
+	if controlSocket != "" {
+		fmt.Printf("제어 소켓: %s (csvfire control --socket %s pause)\n", controlSocket, controlSocket)
+	}
 
 	// 태스크 채널 생성
 	tasksChan := make(chan runner.RowTask, concurrency*2)
 
-	// CSV 읽기 시작
+	// 진행 표시 (--verbose가 아니면 행별 출력 대신 한 줄 진행 표시)
+	// 읽기 시작 전에 세어 두 번의 CSV 읽기가 겹치지 않도록 함
+	totalRows := 0
+	if !verbose {
+		// 행 필터와 선택을 적용한 개수
+		totalRows, err = csvReader.CountRows()
+		if err != nil {
+			totalRows = 0
+		}
+	}
+
+	// CSV 읽기 시작 (실행이 멈추면 나머지 행은 읽지 않음)
+	readCtx, stopReading := context.WithCancel(ctx)
+	defer stopReading()
 	go func() {
-		if err := csvReader.ReadRows(tasksChan); err != nil {
+		if err := csvReader.ReadRows(readCtx, tasksChan); err != nil {
 			fmt.Printf("CSV 읽기 오류: %v\n", err)
 			cancel()
 		}
 	}()
 
+	// 카나리 단계의 응답 샘플
+	samples := &sampleCollector{enabled: canaryRows > 0}
+
 	// 결과 콜백
 	callback := func(rowNum int, validationResult *validator.ValidationResult, requestResult *request.RequestResult) {
 		loggerInstance.LogRequest(rowNum, validationResult, requestResult)
-		
+		samples.add(rowNum, requestResult)
+
+		if !verbose {
+			return
+		}
+
 		if requestResult != nil {
 			if requestResult.Success {
 				fmt.Printf("행 %d: 성공 (상태: %d, 지연: %dms)\n", 
@@ -379,8 +887,106 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// 실행
-	result := runnerInstance.Run(ctx, tasksChan, callback)
+	// 시간대 제한 실행은 며칠에 걸칠 수 있으므로 진행 상황을 주기적으로 저장
+	if requestConfig.Schedule != nil {
+		stopWatching := watchSchedule(runnerInstance, logDir, verbose)
+		defer stopWatching()
+	}
+
+	// 실행 (카나리 → 동시성 단계별 진행)
+	result := &runner.RunResult{}
+	var stopErr error
+	feed := &taskFeed{src: tasksChan}
+	for _, stage := range planStages(canaryRows, ramp, rampStep, concurrency) {
+		if stage.name != "main" {
+			fmt.Printf("\n[%s] 동시성 %d", stage.name, stage.concurrency)
+			if stage.rows > 0 {
+				fmt.Printf(", 최대 %d행", stage.rows)
+			}
+			fmt.Println()
+		}
+		runnerInstance.SetConcurrency(stage.concurrency)
+
+		var progress *progressPrinter
+		if !verbose {
+			progress = newProgressPrinter(runnerInstance, totalRows)
+			progress.Start()
+		}
+		stageDone := make(chan struct{})
+		stageTasks, stageFinished := feed.take(stage.rows, stageDone)
+		stageResult := runnerInstance.Run(ctx, stageTasks, callback)
+		close(stageDone)
+		<-stageFinished
+		if progress != nil {
+			progress.Stop()
+		}
+		result.Add(stageResult)
+
+		if stageResult.Aborted || stageResult.Stopped || ctx.Err() != nil {
+			break
+		}
+
+		if stage.name == "canary" {
+			printCanarySummary(stageResult, samples.stop())
+
+			proceed := false
+			if continueIf != nil {
+				value, ok := continueIf.evaluate(stageResult)
+				proceed = ok
+				if ok {
+					fmt.Printf("\n자동 진행 조건 충족 (%s, 실제 %.1f%%)\n", continueIf, value)
+				} else {
+					fmt.Printf("\n자동 진행 조건 미충족 (%s, 실제 %.1f%%)\n", continueIf, value)
+				}
+			} else {
+				proceed, err = confirmContinue(interrupted)
+				if err != nil {
+					stopErr = err
+					break
+				}
+			}
+
+			if !proceed {
+				stopErr = &exitError{
+					code:    exitCodeCanaryStopped,
+					message: "카나리 단계 후 실행을 멈췄습니다",
+				}
+				fmt.Printf("카나리 단계 후 중단합니다. 남은 행은 --resume으로 이어서 실행할 수 있습니다\n")
+				break
+			}
+		}
+	}
+
+	// 읽었지만 단계가 보내지 않은 행(중단, 카나리 후 멈춤)은 보내지 않은 행으로 기록.
+	// 아직 읽지 않은 행은 로그 없이 개수만 세며, 기록이 없으므로 --resume 시 전송됨
+	stopReading()
+	discarded := runnerInstance.Discard(feed.rest(), callback)
+	result.NotAttempted += discarded
+	result.TotalRows += discarded
+	if unread := totalRows - result.TotalRows; totalRows > 0 && unread > 0 {
+		result.NotAttempted += unread
+		result.TotalRows += unread
+	}
+
+	// 실행 이력 기록
+	plan.manifest.AddAttempt(history.Attempt{
+		Command:      plan.command,
+		Filter:       plan.filter,
+		StartedAt:    result.StartTime,
+		FinishedAt:   result.EndTime,
+		TotalRows:    result.TotalRows,
+		SuccessRows:  result.SuccessRows,
+		FailedRows:   result.FailedRows,
+		SkippedRows:  result.SkippedRows,
+		NotAttempted: result.NotAttempted,
+		AbortReason:  result.AbortReason,
+	})
+	if err := plan.manifest.Save(logDir); err != nil {
+		fmt.Printf("실행 매니페스트 저장 오류: %v\n", err)
+	}
+	if err := history.SaveCheckpoints(logDir, runnerInstance.GetProcessedHashes()); err != nil {
+		fmt.Printf("체크포인트 저장 오류: %v\n", err)
+	}
 
 	// 결과 출력
 	fmt.Printf("\n=== 실행 결과 ===\n")
@@ -388,20 +994,149 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	fmt.Printf("성공: %d\n", result.SuccessRows)
 	fmt.Printf("실패: %d\n", result.FailedRows)
 	fmt.Printf("건너뛴 행: %d\n", result.SkippedRows)
+	if result.NotAttempted > 0 {
+		fmt.Printf("보내지 않은 행: %d (실행을 멈춰 전송하지 않음, --resume으로 이어서 실행)\n", result.NotAttempted)
+	}
 	fmt.Printf("실행 시간: %v\n", result.Duration)
+	if result.BreakerTrips > 0 && !result.Aborted {
+		fmt.Printf("회로 차단기 일시 중지: %d회\n", result.BreakerTrips)
+	}
+	if orderedOutput && result.OrderStalls > 0 {
+		fmt.Printf("순서 유지 대기: %d회 (총 %v, 느린 행이 창을 채움)\n", result.OrderStalls, result.OrderStallTime.Round(time.Millisecond))
+	}
+
+	if mockServer != nil {
+		fmt.Printf("\n=== 모의 서버 응답 분포 ===\n")
+		stats := mockServer.Stats()
+		outcomes := make([]string, 0, len(stats))
+		for outcome := range stats {
+			outcomes = append(outcomes, outcome)
+		}
+		sort.Strings(outcomes)
+		for _, outcome := range outcomes {
+			fmt.Printf("%s: %d\n", outcome, stats[outcome])
+		}
+	}
+
+	if recorder != nil {
+		fmt.Printf("기록된 요청/응답: %d건 (%s)\n", recorder.Count(), recordFile)
+	}
+
+	if replayer != nil {
+		mismatches := replayer.Mismatches()
+		fmt.Printf("\n=== 재생 결과 ===\n")
+		fmt.Printf("재생된 응답: %d\n", replayer.Hits())
+		fmt.Printf("불일치 요청: %d\n", len(mismatches))
+		if len(mismatches) > 0 {
+			mismatchFile := filepath.Join(logDir, "replay_mismatches.csv")
+			if err := replayer.WriteMismatches(mismatchFile); err != nil {
+				fmt.Printf("불일치 리포트 작성 오류: %v\n", err)
+			} else {
+				fmt.Printf("불일치 리포트: %s\n", mismatchFile)
+			}
+			for i, mismatch := range mismatches {
+				if i >= 5 {
+					break
+				}
+				fmt.Printf("  %s [%s] %s\n", mismatch.RequestID, mismatch.Kind, mismatch.Detail)
+			}
+		}
+	}
 
 	// 실패한 행 내보내기
 	if exportFailed != "" && loggerInstance.GetFailedRowCount() > 0 {
-		if err := loggerInstance.ExportFailedRows(exportFailed); err != nil {
-			fmt.Printf("실패한 행 내보내기 오류: %v\n", err)
-		} else {
-			fmt.Printf("실패한 행 내보냄: %s\n", exportFailed)
+		fmt.Printf("실패한 행 내보냄: %s (%d행)\n", exportFailed, loggerInstance.GetFailedRowCount())
+	}
+
+	if stopErr != nil {
+		return stopErr
+	}
+
+	if result.Aborted {
+		fmt.Printf("\n회로 차단기가 실행을 중단했습니다: %s\n", result.AbortReason)
+		fmt.Printf("진행 중이던 요청은 기록되었으며, 남은 행은 --resume으로 이어서 실행할 수 있습니다\n")
+		return &exitError{
+			code:    exitCodeAborted,
+			message: fmt.Sprintf("회로 차단기로 실행 중단: %s", result.AbortReason),
 		}
 	}
 
 	return nil
 }
 
+func runReport(cmd *cobra.Command, args []string) error {
+	outDir := reportOut
+	if outDir == "" {
+		outDir = runDir
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("리포트 디렉토리 생성 실패: %w", err)
+	}
+
+	rep, err := report.Generate(runDir)
+	if err != nil {
+		return fmt.Errorf("리포트 생성 실패: %w", err)
+	}
+
+	// 로그 파일 링크는 리포트 위치 기준 상대 경로로 작성
+	for i, link := range rep.Links {
+		rep.Links[i].Path = relativePath(outDir, filepath.Join(runDir, link.Path))
+	}
+	if failedFile != "" {
+		rep.AddLink(filepath.Base(failedFile), relativePath(outDir, failedFile))
+	}
+
+	htmlFile := filepath.Join(outDir, "report.html")
+	if err := rep.WriteHTML(htmlFile); err != nil {
+		return err
+	}
+
+	markdownFile := filepath.Join(outDir, "report.md")
+	if err := rep.WriteMarkdown(markdownFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("=== 리포트 요약 ===\n")
+	fmt.Printf("총 행 수: %d\n", rep.TotalRows)
+	fmt.Printf("성공: %d\n", rep.SuccessRows)
+	fmt.Printf("실패: %d\n", rep.FailedRows)
+	if rep.NotAttempted > 0 {
+		fmt.Printf("보내지 않은 행: %d\n", rep.NotAttempted)
+	}
+	fmt.Printf("지연 시간 p50/p95/p99: %d/%d/%dms\n", rep.LatencyP50, rep.LatencyP95, rep.LatencyP99)
+	fmt.Printf("HTML 리포트: %s\n", htmlFile)
+	fmt.Printf("Markdown 리포트: %s\n", markdownFile)
+
+	return nil
+}
+
+// loadMockConfig loads the mock script, or builds a default one that always
+// answers with a response satisfying the request's success conditions
+func loadMockConfig(requestConfig *config.RequestConfig) (*config.MockConfig, error) {
+	if mockFile != "" {
+		return config.LoadMockConfig(mockFile)
+	}
+
+	return config.DefaultMockConfig(requestConfig)
+}
+
+// relativePath returns target relative to base, falling back to target itself
+func relativePath(base, target string) string {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return target
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return target
+	}
+	rel, err := filepath.Rel(absBase, absTarget)
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
 func writeValidationReport(filename string, errors []validator.ValidationError) error {
 	file, err := os.Create(filename)
 	if err != nil {
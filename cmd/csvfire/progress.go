@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"csvfire/internal/runner"
+)
+
+// progressPrinter renders a single-line progress display for a running Runner
+type progressPrinter struct {
+	runner    *runner.Runner
+	totalRows int
+	startTime time.Time
+	startRows int // Rows processed before Start (earlier stages), excluded from the rate
+	lastWidth int
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+}
+
+// newProgressPrinter creates a progress printer; totalRows may be 0 if unknown
+func newProgressPrinter(r *runner.Runner, totalRows int) *progressPrinter {
+	return &progressPrinter{
+		runner:    r,
+		totalRows: totalRows,
+		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
+	}
+}
+
+// Start starts refreshing the progress line in the background
+func (p *progressPrinter) Start() {
+	p.startTime = time.Now()
+	p.startRows = processedRows(p.runner.Progress())
+
+	go func() {
+		defer close(p.doneChan)
+
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-p.stopChan:
+				p.print()
+				fmt.Println()
+				return
+			}
+		}
+	}()
+}
+
+// Stop prints the final progress line and stops refreshing
+func (p *progressPrinter) Stop() {
+	close(p.stopChan)
+	<-p.doneChan
+}
+
+// print overwrites the current terminal line with the latest progress
+func (p *progressPrinter) print() {
+	progress := p.runner.Progress()
+	processed := processedRows(progress)
+	elapsed := time.Since(p.startTime)
+
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(processed-p.startRows) / elapsed.Seconds()
+	}
+
+	var line string
+	if p.totalRows > 0 {
+		percent := float64(processed) / float64(p.totalRows) * 100
+		eta := "-"
+		if rate > 0 && processed < p.totalRows {
+			remaining := time.Duration(float64(p.totalRows-processed) / rate * float64(time.Second))
+			eta = remaining.Round(time.Second).String()
+		}
+		line = fmt.Sprintf("진행: %d/%d (%.1f%%) | 성공 %d | 실패 %d | 건너뜀 %d | %.1f행/s | 남은 시간 %s",
+			processed, p.totalRows, percent, progress.SuccessRows, progress.FailedRows, progress.SkippedRows, rate, eta)
+	} else {
+		line = fmt.Sprintf("진행: %d | 성공 %d | 실패 %d | 건너뜀 %d | %.1f행/s",
+			processed, progress.SuccessRows, progress.FailedRows, progress.SkippedRows, rate)
+	}
+
+	if progress.Paused {
+		line += " | 일시정지"
+	}
+	if progress.NotAttempted > 0 {
+		line += fmt.Sprintf(" | 미전송 %d", progress.NotAttempted)
+	}
+	switch progress.BreakerState {
+	case runner.BreakerOpen, runner.BreakerHalfOpen:
+		line += " | 회로 차단기 일시 중지"
+	case runner.BreakerAborted:
+		line += " | 회로 차단기 중단"
+	}
+	if !progress.ResumeAt.IsZero() {
+		line += " | 허용 시간대 밖, 재개 " + formatResumeAt(progress.ResumeAt)
+	}
+
+	// Pad with spaces so a shorter line fully overwrites the previous one
+	width := len([]rune(line))
+	padding := ""
+	if width < p.lastWidth {
+		padding = strings.Repeat(" ", p.lastWidth-width)
+	}
+	p.lastWidth = width
+
+	fmt.Printf("\r%s%s", line, padding)
+}
+
+// processedRows returns the number of rows with a result, including rows
+// left unsent by a shutdown
+func processedRows(progress runner.Progress) int {
+	return progress.SuccessRows + progress.FailedRows + progress.SkippedRows + progress.NotAttempted
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"csvfire/internal/config"
+	"csvfire/internal/history"
+	"csvfire/internal/runner"
+)
+
+// checkpointSaveInterval is how often checkpoints are saved during a scheduled
+// run, so a campaign spanning several days survives a restart with --resume
+const checkpointSaveInterval = time.Minute
+
+// describeSchedule returns the time windows of a schedule for display
+func describeSchedule(schedule *config.ScheduleConfig) string {
+	timezone := schedule.Timezone
+	if timezone == "" {
+		timezone = "로컬 시간"
+	}
+
+	windows := make([]string, 0, len(schedule.Windows))
+	for _, window := range schedule.Windows {
+		text := window.Start + "-" + window.End
+		if len(window.Days) > 0 {
+			text += " " + strings.Join(window.Days, ",")
+		}
+		if window.Rate > 0 {
+			text += fmt.Sprintf(" @%.1f/s", window.Rate)
+		}
+		windows = append(windows, text)
+	}
+
+	return fmt.Sprintf("%s (%s)", strings.Join(windows, ", "), timezone)
+}
+
+// formatResumeAt formats the next window start for status output
+func formatResumeAt(resumeAt time.Time) string {
+	return resumeAt.Format("2006-01-02 15:04 MST")
+}
+
+// watchSchedule saves checkpoints periodically while a scheduled run is in
+// progress and, in verbose mode, reports when sending pauses until the next
+// window. It returns a function that stops watching.
+func watchSchedule(r *runner.Runner, logDir string, verbose bool) func() {
+	stopChan := make(chan struct{})
+	doneChan := make(chan struct{})
+
+	go func() {
+		defer close(doneChan)
+
+		saveTicker := time.NewTicker(checkpointSaveInterval)
+		defer saveTicker.Stop()
+		statusTicker := time.NewTicker(time.Second)
+		defer statusTicker.Stop()
+
+		var waitingUntil time.Time
+		for {
+			select {
+			case <-saveTicker.C:
+				if err := history.SaveCheckpoints(logDir, r.GetProcessedHashes()); err != nil {
+					fmt.Printf("체크포인트 저장 오류: %v\n", err)
+				}
+			case <-statusTicker.C:
+				resumeAt := r.Progress().ResumeAt
+				if !resumeAt.IsZero() && !resumeAt.Equal(waitingUntil) {
+					// Persist progress when sending pauses until the next window
+					if err := history.SaveCheckpoints(logDir, r.GetProcessedHashes()); err != nil {
+						fmt.Printf("체크포인트 저장 오류: %v\n", err)
+					}
+					if verbose {
+						fmt.Printf("허용 시간대 밖: %s에 재개합니다\n", formatResumeAt(resumeAt))
+					}
+				}
+				waitingUntil = resumeAt
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stopChan)
+		<-doneChan
+	}
+}
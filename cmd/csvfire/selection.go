@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"csvfire/internal/config"
+	"csvfire/internal/reader"
+	"csvfire/internal/validator"
+)
+
+var (
+	selectRows  string
+	selectWhere string
+	sampleRate  float64
+	sampleSeed  int64
+	shardSpec   string
+	shardKey    string
+)
+
+// addSelectionFlags registers the row selection flags shared by validate, render and run
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&selectRows, "rows", "", "처리할 행 번호 범위 (예: 1000-1999, 1-10,50,100-)")
+	cmd.Flags().StringVar(&selectWhere, "where", "", "행 선택 조건식 (예: gender == \"F\", norm.phone startsWith \"+8210\")")
+	cmd.Flags().Float64Var(&sampleRate, "sample", 0, "무작위로 선택할 행 비율 (예: 0.01)")
+	cmd.Flags().Int64Var(&sampleSeed, "seed", 0, "샘플링 시드 (기본값: 무작위, 같은 시드는 같은 행을 선택)")
+}
+
+// addShardFlags registers the flags selecting one shard of a CSV split across hosts
+func addShardFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&shardSpec, "shard", "", "여러 호스트로 나눠 실행할 때 이 호스트의 샤드 (예: 2/5)")
+	cmd.Flags().StringVar(&shardKey, "shard-key", "", "샤드 배정에 사용할 키 컬럼 (기본값: 행 번호)")
+}
+
+// buildSelection creates the row selection from the selection and shard flags.
+// Normalized values for norm.<column> come from the schema validator.
+func buildSelection(cmd *cobra.Command, schema *config.Schema) (*reader.Selection, error) {
+	if sampleRate > 0 && !cmd.Flags().Changed("seed") {
+		sampleSeed = time.Now().UnixNano()
+	}
+
+	selection, err := reader.NewSelection(schema, selectRows, selectWhere, sampleRate, sampleSeed)
+	if err != nil {
+		return nil, fmt.Errorf("행 선택 옵션 오류: %w", err)
+	}
+	if shardSpec != "" {
+		if err := selection.SetShard(schema, shardSpec, shardKey); err != nil {
+			return nil, fmt.Errorf("샤드 옵션 오류: %w", err)
+		}
+	} else if shardKey != "" {
+		return nil, fmt.Errorf("--shard-key는 --shard와 함께 사용해야 합니다")
+	}
+
+	// Rows are normalized by every pass over the CSV (counting and reading),
+	// so the normalizer must not track uniqueness
+	selection.SetNormalizer(validator.NewValidator(schema).Normalize)
+
+	if !selection.IsEmpty() {
+		fmt.Printf("행 선택: %s\n", selection)
+	}
+
+	return selection, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"csvfire/internal/server"
+)
+
+var (
+	serveAddr    string
+	serveDataDir string
+	serveMaxJobs int
+	serveToken   string
+)
+
+// serveShutdownTimeout bounds how long open API requests may delay shutdown
+const serveShutdownTimeout = 10 * time.Second
+
+// addServeFlags registers the flags of the serve command
+func addServeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&serveAddr, "addr", ":8080", "API 서버 주소")
+	cmd.Flags().StringVar(&serveDataDir, "data", "csvfire-data", "작업 파일과 로그를 저장할 디렉토리")
+	cmd.Flags().IntVar(&serveMaxJobs, "max-jobs", 2, "동시에 실행할 최대 작업 수")
+	cmd.Flags().StringVar(&serveToken, "token", "", "API 요청에 필요한 Bearer 토큰 (기본값: 환경변수 CSVFIRE_TOKEN)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("CSVFIRE_TOKEN")
+	}
+
+	manager, err := server.NewManager(serveDataDir, serveMaxJobs)
+	if err != nil {
+		return fmt.Errorf("작업 관리자 생성 실패: %w", err)
+	}
+
+	// Cancelling the base context ends open event streams on shutdown
+	baseCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+
+	httpServer := &http.Server{
+		Addr:        serveAddr,
+		Handler:     server.NewServer(manager, token),
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	fmt.Printf("API 서버를 시작합니다: %s\n", serveAddr)
+	fmt.Printf("데이터 디렉토리: %s\n", serveDataDir)
+	fmt.Printf("동시 실행 작업 수: %d\n", serveMaxJobs)
+	if token == "" {
+		fmt.Printf("경고: 토큰이 설정되지 않아 인증 없이 API를 사용할 수 있습니다\n")
+	}
+
+	if recovered := manager.Recover(); len(recovered) > 0 {
+		fmt.Printf("중단된 작업 %d개를 이어서 실행합니다: %s\n", len(recovered), strings.Join(recovered, ", "))
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	// The first signal stops the jobs from sending new requests; a second
+	// signal or the grace timeout cancels the requests still in flight
+	drainCtx, abort := context.WithCancel(context.Background())
+	defer abort()
+	interrupted := make(chan struct{})
+	stopWatchingInterrupt := watchInterrupt(func() { close(interrupted) }, abort)
+	defer stopWatchingInterrupt()
+
+	select {
+	case err := <-serveErr:
+		abort()
+		manager.Shutdown(drainCtx)
+		return fmt.Errorf("API 서버 실행 실패: %w", err)
+	case <-interrupted:
+	}
+
+	stopStreams()
+	ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("API 서버 종료 오류: %v\n", err)
+	}
+	manager.Shutdown(drainCtx)
+
+	fmt.Printf("종료했습니다. 실행 중이던 작업은 다음 시작 때 이어서 실행됩니다\n")
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var graceTimeout time.Duration
+
+// defaultGraceTimeout is how long in-flight requests may finish after the first interrupt
+const defaultGraceTimeout = 30 * time.Second
+
+// addShutdownFlags registers the graceful shutdown flags
+func addShutdownFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&graceTimeout, "grace-timeout", defaultGraceTimeout, "첫 중단 신호 후 진행 중인 요청을 기다리는 최대 시간")
+}
+
+// watchInterrupt implements a two-phase shutdown. The first SIGINT/SIGTERM
+// calls drain, which must stop sending new requests; once graceTimeout passes
+// or a second signal arrives, abort cancels the requests still in flight.
+// It returns a function that stops watching.
+func watchInterrupt(drain, abort func()) func() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+
+		fmt.Printf("\n중단 신호 수신: 새 요청을 보내지 않고 진행 중인 요청을 최대 %v 기다립니다 (한 번 더 누르면 즉시 중단)\n", graceTimeout)
+		drain()
+
+		timer := time.NewTimer(graceTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-signals:
+			fmt.Printf("\n즉시 중단: 진행 중인 요청을 취소합니다\n")
+		case <-timer.C:
+			fmt.Printf("\n대기 시간 초과: 진행 중인 요청을 취소합니다\n")
+		case <-done:
+			return
+		}
+		abort()
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,205 @@
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ignoredHeaders are excluded from the canonical request hash because they
+// change on every run without affecting the request semantics
+var ignoredHeaders = map[string]bool{
+	"Traceparent": true,
+	"Tracestate":  true,
+	"Baggage":     true,
+}
+
+// Redacted replaces the value of a secret header in a cassette
+const Redacted = "[REDACTED]"
+
+// secretHeaders are always redacted when recording. The hash is computed over
+// the redacted form, so a rotated token still matches its recording.
+var secretHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"Api-Key",
+	"X-Auth-Token",
+	"X-Access-Token",
+}
+
+// redactSet returns the canonical names of the built-in secret headers and extra
+func redactSet(extra []string) map[string]bool {
+	set := make(map[string]bool, len(secretHeaders)+len(extra))
+	for _, name := range secretHeaders {
+		set[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range extra {
+		if name = strings.TrimSpace(name); name != "" {
+			set[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	return set
+}
+
+// Interaction is a single recorded request/response exchange
+type Interaction struct {
+	Key       string          `json:"key"`
+	RequestID string          `json:"request_id,omitempty"`
+	Request   RecordedRequest `json:"request"`
+	Response  *RecordedBody   `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// RecordedRequest is the canonical form of a request
+type RecordedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// RecordedBody is a recorded response
+type RecordedBody struct {
+	StatusCode int                 `json:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	Base64     bool                `json:"base64,omitempty"`
+}
+
+// canonicalRequest reads the request body and returns its canonical form and hash.
+// Headers in redact are stored as Redacted. The request body is replaced so the
+// request can still be sent.
+func canonicalRequest(req *http.Request, redact map[string]bool) (RecordedRequest, string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return RecordedRequest{}, "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := RecordedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: make(map[string]string),
+		Body:    string(body),
+	}
+	for key, values := range req.Header {
+		key = http.CanonicalHeaderKey(key)
+		switch {
+		case ignoredHeaders[key]:
+			continue
+		case redact[key]:
+			recorded.Headers[key] = Redacted
+		default:
+			recorded.Headers[key] = strings.Join(values, ",")
+		}
+	}
+
+	return recorded, hashRequest(recorded), nil
+}
+
+// hashRequest computes the canonical request hash
+func hashRequest(recorded RecordedRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "method:%s\n", recorded.Method)
+	fmt.Fprintf(h, "url:%s\n", recorded.URL)
+
+	keys := make([]string, 0, len(recorded.Headers))
+	for key := range recorded.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "header:%s=%s\n", key, recorded.Headers[key])
+	}
+
+	fmt.Fprintf(h, "body:%s\n", recorded.Body)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// recordResponse converts a response to its recorded form with the headers in
+// redact masked. The response body is replaced so the caller can still read it.
+func recordResponse(resp *http.Response, redact map[string]bool) (*RecordedBody, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := &RecordedBody{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header.Clone(),
+	}
+	for key := range recorded.Headers {
+		if redact[http.CanonicalHeaderKey(key)] {
+			recorded.Headers[key] = []string{Redacted}
+		}
+	}
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+	} else {
+		recorded.Body = base64.StdEncoding.EncodeToString(body)
+		recorded.Base64 = true
+	}
+
+	return recorded, nil
+}
+
+// bodyBytes returns the decoded response body
+func (b *RecordedBody) bodyBytes() []byte {
+	if b.Base64 {
+		data, err := base64.StdEncoding.DecodeString(b.Body)
+		if err == nil {
+			return data
+		}
+	}
+	return []byte(b.Body)
+}
+
+// Load reads all interactions of a cassette file
+func Load(filename string) ([]Interaction, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette line %d: %w", line, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	return interactions, nil
+}
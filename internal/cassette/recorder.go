@@ -0,0 +1,112 @@
+package cassette
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"csvfire/internal/request"
+)
+
+// Recorder appends every exchange to a cassette file as JSON lines
+type Recorder struct {
+	file   *os.File
+	writer *bufio.Writer
+	redact map[string]bool
+	mu     sync.Mutex
+	count  int
+}
+
+// NewRecorder creates (or truncates) a cassette file for recording. Values of
+// the built-in secret headers and of redactHeaders are never written to it.
+func NewRecorder(filename string, redactHeaders []string) (*Recorder, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+
+	return &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		redact: redactSet(redactHeaders),
+	}, nil
+}
+
+// Wrap returns a transport that records exchanges sent through base
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: r, base: base}
+}
+
+// Count returns the number of recorded interactions
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Close flushes and closes the cassette file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("failed to flush cassette: %w", err)
+	}
+	return r.file.Close()
+}
+
+// record appends an interaction to the cassette
+func (r *Recorder) record(interaction Interaction) {
+	data, err := json.Marshal(interaction)
+	if err != nil {
+		fmt.Printf("Error encoding cassette entry: %v\n", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writer.Write(data)
+	r.writer.WriteByte('\n')
+	r.writer.Flush()
+	r.count++
+}
+
+// recordingTransport forwards requests and records the exchanges
+type recordingTransport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, key, err := canonicalRequest(req, t.recorder.redact)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Key:       key,
+		RequestID: request.RequestIDFromContext(req.Context()),
+		Request:   recorded,
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		interaction.Error = err.Error()
+		t.recorder.record(interaction)
+		return nil, err
+	}
+
+	interaction.Response, err = recordResponse(resp, t.recorder.redact)
+	if err != nil {
+		return nil, err
+	}
+
+	t.recorder.record(interaction)
+	return resp, nil
+}
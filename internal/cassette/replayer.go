@@ -0,0 +1,265 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"csvfire/internal/request"
+)
+
+// Mismatch kinds reported by the replayer
+const (
+	MismatchUnmatched = "unmatched" // The request was never recorded
+	MismatchChanged   = "changed"   // The row was recorded but its rendered request differs
+)
+
+// Mismatch describes a request the cassette could not answer
+type Mismatch struct {
+	RequestID string
+	Kind      string
+	Method    string
+	URL       string
+	Detail    string
+}
+
+// Replayer serves recorded responses without network access
+type Replayer struct {
+	byKey       map[string][]Interaction
+	byRequestID map[string]RecordedRequest
+	redact      map[string]bool
+
+	mu         sync.Mutex
+	served     map[string]int
+	mismatches []Mismatch
+	hits       int
+}
+
+// NewReplayer loads a cassette file for replay. Headers recorded as Redacted
+// are redacted again before matching, so replay needs no redaction options.
+func NewReplayer(filename string) (*Replayer, error) {
+	interactions, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replayer{
+		byKey:       make(map[string][]Interaction),
+		byRequestID: make(map[string]RecordedRequest),
+		served:      make(map[string]int),
+		redact:      redactSet(nil),
+	}
+	for _, interaction := range interactions {
+		for key, value := range interaction.Request.Headers {
+			if value == Redacted {
+				r.redact[key] = true
+			}
+		}
+		r.byKey[interaction.Key] = append(r.byKey[interaction.Key], interaction)
+		if interaction.RequestID != "" {
+			r.byRequestID[interaction.RequestID] = interaction.Request
+		}
+	}
+
+	return r, nil
+}
+
+// Size returns the number of distinct recorded requests
+func (r *Replayer) Size() int {
+	return len(r.byKey)
+}
+
+// Hits returns the number of requests answered from the cassette
+func (r *Replayer) Hits() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hits
+}
+
+// Mismatches returns the requests that could not be answered, ordered by request ID
+func (r *Replayer) Mismatches() []Mismatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := append([]Mismatch(nil), r.mismatches...)
+	sort.Slice(result, func(i, j int) bool { return result[i].RequestID < result[j].RequestID })
+	return result
+}
+
+// WriteMismatches writes the mismatch report as CSV
+func (r *Replayer) WriteMismatches(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create mismatch report: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"request_id", "kind", "method", "url", "detail"}); err != nil {
+		return fmt.Errorf("failed to write mismatch header: %w", err)
+	}
+	for _, mismatch := range r.Mismatches() {
+		record := []string{mismatch.RequestID, mismatch.Kind, mismatch.Method, mismatch.URL, mismatch.Detail}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write mismatch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Transport returns a RoundTripper answering requests from the cassette
+func (r *Replayer) Transport() http.RoundTripper {
+	return &replayTransport{replayer: r}
+}
+
+// replayTransport serves recorded interactions
+type replayTransport struct {
+	replayer *Replayer
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, key, err := canonicalRequest(req, t.replayer.redact)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction, ok := t.replayer.next(key)
+	if !ok {
+		return nil, t.replayer.mismatch(request.RequestIDFromContext(req.Context()), recorded)
+	}
+
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+
+	response := interaction.Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		StatusCode:    response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(response.Headers).Clone(),
+		Body:          io.NopCloser(bytes.NewReader(response.bodyBytes())),
+		ContentLength: int64(len(response.bodyBytes())),
+		Request:       req,
+	}, nil
+}
+
+// next returns the next recorded interaction for a key. Repeated attempts of
+// the same request are served in recording order; the last one is repeated.
+func (r *Replayer) next(key string) (Interaction, bool) {
+	interactions := r.byKey[key]
+	if len(interactions) == 0 {
+		return Interaction{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := r.served[key]
+	if index >= len(interactions) {
+		index = len(interactions) - 1
+	}
+	r.served[key]++
+	r.hits++
+
+	return interactions[index], true
+}
+
+// mismatch records and returns an error for a request without a recorded match
+func (r *Replayer) mismatch(requestID string, recorded RecordedRequest) error {
+	mismatch := Mismatch{
+		RequestID: requestID,
+		Kind:      MismatchUnmatched,
+		Method:    recorded.Method,
+		URL:       recorded.URL,
+		Detail:    "no recorded request",
+	}
+	if previous, ok := r.byRequestID[requestID]; ok && requestID != "" {
+		mismatch.Kind = MismatchChanged
+		mismatch.Detail = describeChanges(previous, recorded)
+	}
+
+	r.mu.Lock()
+	r.mismatches = append(r.mismatches, mismatch)
+	r.mu.Unlock()
+
+	return fmt.Errorf("%w (%s: %s)", request.ErrReplayMismatch, mismatch.Kind, mismatch.Detail)
+}
+
+// describeChanges summarizes how a rendered request differs from its recording
+func describeChanges(previous, current RecordedRequest) string {
+	var changes []string
+	if previous.Method != current.Method {
+		changes = append(changes, fmt.Sprintf("method %s -> %s", previous.Method, current.Method))
+	}
+	if previous.URL != current.URL {
+		changes = append(changes, fmt.Sprintf("url %s -> %s", previous.URL, current.URL))
+	}
+
+	keys := make(map[string]bool)
+	for key := range previous.Headers {
+		keys[key] = true
+	}
+	for key := range current.Headers {
+		keys[key] = true
+	}
+	var headerChanges []string
+	for key := range keys {
+		if previous.Headers[key] != current.Headers[key] {
+			headerChanges = append(headerChanges, key)
+		}
+	}
+	if len(headerChanges) > 0 {
+		sort.Strings(headerChanges)
+		changes = append(changes, "headers "+strings.Join(headerChanges, ","))
+	}
+
+	if previous.Body != current.Body {
+		changes = append(changes, describeBodyChanges(previous.Body, current.Body))
+	}
+
+	return strings.Join(changes, "; ")
+}
+
+// describeBodyChanges lists the changed top-level fields of JSON bodies
+func describeBodyChanges(previous, current string) string {
+	var previousJSON, currentJSON map[string]interface{}
+	if json.Unmarshal([]byte(previous), &previousJSON) != nil || json.Unmarshal([]byte(current), &currentJSON) != nil {
+		return "body"
+	}
+
+	keys := make(map[string]bool)
+	for key := range previousJSON {
+		keys[key] = true
+	}
+	for key := range currentJSON {
+		keys[key] = true
+	}
+
+	var fields []string
+	for key := range keys {
+		if !reflect.DeepEqual(previousJSON[key], currentJSON[key]) {
+			fields = append(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return "body formatting"
+	}
+	sort.Strings(fields)
+	return "body fields " + strings.Join(fields, ",")
+}
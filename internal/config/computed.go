@@ -0,0 +1,151 @@
+package config
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"csvfire/internal/funcs"
+)
+
+// RowNumberField is the variable holding the source row number in computed
+// column expressions and templates
+const RowNumberField = "row_number"
+
+// ComputedColumn is a column derived from the other values of a row by an
+// expression or a template. It accepts the rules of a column, and its
+// normalized value is part of the row data like any other column.
+type ComputedColumn struct {
+	ColumnSchema `yaml:",inline"`
+	Expr         string `yaml:"expr,omitempty"`
+	Template     string `yaml:"template,omitempty"`
+
+	Program         *vm.Program        `yaml:"-"` // Compiled when the schema is loaded
+	TemplateProgram *template.Template `yaml:"-"`
+}
+
+// ComputedEnv returns the variables computed column expressions are evaluated
+// with: the variables of conditions, the row number, and the age, sha256 and
+// uuid functions unless a column has the same name. uuid is derived from the
+// row number and raw values, so a row keeps its UUID when resumed or retried.
+func ComputedEnv(values map[string]any, raw, norm map[string]string, rowNum int) map[string]any {
+	env := ConditionEnv(values, raw, norm)
+	builtins := map[string]any{
+		RowNumberField: rowNum,
+		"age":          age,
+		"sha256": func(value any) string {
+			sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+			return hex.EncodeToString(sum[:])
+		},
+		"uuid": func() string {
+			return rowUUID(rowNum, raw)
+		},
+	}
+	// Columns take precedence over builtins of the same name
+	for name, value := range builtins {
+		if _, exists := env[name]; !exists {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// age returns the age in full years of a birth date
+func age(birth any) (int, error) {
+	date, ok := birth.(time.Time)
+	if !ok {
+		return 0, fmt.Errorf("age needs a date, got %T", birth)
+	}
+	now := time.Now()
+	years := now.Year() - date.Year()
+	if date.After(now.AddDate(-years, 0, 0)) {
+		years--
+	}
+	return years, nil
+}
+
+// rowUUID returns a name-based (version 5 layout) UUID for a row
+func rowUUID(rowNum int, raw map[string]string) string {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	h := sha1.New()
+	fmt.Fprintf(h, "row:%d\n", rowNum)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, raw[key])
+	}
+	sum := h.Sum(nil)
+
+	sum[6] = sum[6]&0x0f | 0x50 // Version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// validateComputed checks the computed columns of a schema and compiles their
+// expressions and templates
+func validateComputed(schema *Schema) error {
+	names := map[string]bool{"raw": true, "norm": true}
+	env := map[string]any{}
+	for _, col := range schema.Columns {
+		names[col.Name] = true
+		env[col.Name] = ValuePlaceholder(col.Type)
+	}
+	for _, rule := range schema.Enrich {
+		for field := range rule.Fields {
+			names[field] = true
+			env[field] = ""
+		}
+	}
+
+	for i := range schema.Computed {
+		col := &schema.Computed[i]
+		if col.Name == "" {
+			return fmt.Errorf("computed column name cannot be empty")
+		}
+		if names[col.Name] {
+			return fmt.Errorf("computed column '%s' is already a column or field", col.Name)
+		}
+		if col.Type == "" {
+			col.Type = "string"
+		}
+		if err := validateColumn(&col.ColumnSchema, col.Name, &schema.Rules); err != nil {
+			return err
+		}
+		if col.RequiredIf != nil || col.ForbiddenIf != nil || len(col.Compare) > 0 || col.InLookup != "" {
+			return fmt.Errorf("required_if, forbidden_if, compare and in_lookup are not supported for computed columns (column '%s')", col.Name)
+		}
+
+		switch {
+		case (col.Expr == "") == (col.Template == ""):
+			return fmt.Errorf("computed column '%s' needs either expr or template", col.Name)
+		case col.Expr != "":
+			program, err := expr.Compile(col.Expr, expr.Env(ComputedEnv(env, map[string]string{}, map[string]string{}, 0)))
+			if err != nil {
+				return fmt.Errorf("invalid expr for computed column '%s': %w", col.Name, err)
+			}
+			col.Program = program
+		default:
+			tmpl, err := template.New(col.Name).Funcs(funcs.Map()).Parse(col.Template)
+			if err != nil {
+				return fmt.Errorf("invalid template for computed column '%s': %w", col.Name, err)
+			}
+			col.TemplateProgram = tmpl
+		}
+
+		// Earlier computed columns are available to later ones
+		names[col.Name] = true
+		env[col.Name] = ValuePlaceholder(col.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// CompareRule constrains a column relative to another column of the row,
+// e.g. an end date after the start date
+type CompareRule struct {
+	Op      string `yaml:"op"`     // >, >=, <, <=, ==, !=
+	Column  string `yaml:"column"` // Column the value is compared with
+	Message string `yaml:"message,omitempty"`
+}
+
+// CompareOps lists the operators of compare rules
+var CompareOps = []string{">", ">=", "<", "<=", "==", "!="}
+
+// Condition is a boolean expression over the normalized values of a row, used
+// by required_if and forbidden_if
+type Condition struct {
+	Expr    string
+	Program *vm.Program // Compiled when the schema is loaded
+}
+
+// UnmarshalYAML reads a condition from its expression
+func (c *Condition) UnmarshalYAML(node *yaml.Node) error {
+	return node.Decode(&c.Expr)
+}
+
+// MarshalYAML writes a condition as its expression
+func (c Condition) MarshalYAML() (any, error) {
+	return c.Expr, nil
+}
+
+// ConditionEnv returns the variables conditions are evaluated with: every
+// column as its typed value, and the raw and norm maps of the CSV and
+// normalized strings for names that aren't valid identifiers
+func ConditionEnv(values map[string]any, raw, norm map[string]string) map[string]any {
+	env := make(map[string]any, len(values)+2)
+	for name, value := range values {
+		env[name] = value
+	}
+	env["raw"] = raw
+	env["norm"] = norm
+	return env
+}
+
+// ValuePlaceholder returns a value of the type the values of a column type
+// have, so that expressions over row values are type checked when compiled.
+// JSON values have no fixed type.
+func ValuePlaceholder(colType string) any {
+	switch {
+	case colType == "int":
+		return int64(0)
+	case colType == "float":
+		return float64(0)
+	case strings.HasPrefix(colType, "decimal("):
+		return decimal.Decimal{}
+	case colType == "bool":
+		return false
+	case colType == "list":
+		return []any{}
+	case colType == "json":
+		return nil
+	case strings.HasPrefix(colType, "date"):
+		return time.Time{}
+	}
+	return ""
+}
+
+// validateConditions checks the cross-column rules of the schema columns and
+// compiles their conditions
+func validateConditions(schema *Schema) error {
+	env := map[string]any{}
+	for _, col := range schema.Columns {
+		env[col.Name] = ValuePlaceholder(col.Type)
+	}
+	env = ConditionEnv(env, map[string]string{}, map[string]string{})
+
+	for i := range schema.Columns {
+		col := &schema.Columns[i]
+
+		if col.Required && col.RequiredIf != nil {
+			return fmt.Errorf("required and required_if cannot be combined (column '%s')", col.Name)
+		}
+		for _, cond := range []*Condition{col.RequiredIf, col.ForbiddenIf} {
+			if cond == nil {
+				continue
+			}
+			program, err := expr.Compile(cond.Expr, expr.Env(env), expr.AsBool())
+			if err != nil {
+				return fmt.Errorf("invalid condition for column '%s': %w", col.Name, err)
+			}
+			cond.Program = program
+		}
+
+		for _, rule := range col.Compare {
+			if !slices.Contains(CompareOps, rule.Op) {
+				return fmt.Errorf("invalid compare op '%s' for column '%s' (available: %s)",
+					rule.Op, col.Name, strings.Join(CompareOps, ", "))
+			}
+			if rule.Column == col.Name || schema.GetColumnByName(rule.Column) == nil {
+				return fmt.Errorf("compare column '%s' of column '%s' is not another schema column", rule.Column, col.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"csvfire/internal/lookup"
+)
+
+// reservedFields are names of template and expression data that enrich fields
+// cannot take
+var reservedFields = []string{"raw", "norm"}
+
+// LookupSource is a reference table of the lookups section
+type LookupSource struct {
+	File  string `yaml:"file"`            // Relative to the schema
+	Type  string `yaml:"type,omitempty"`  // csv, json or sqlite (default: from the file extension)
+	Query string `yaml:"query,omitempty"` // sqlite: query returning the table
+
+	Table *lookup.Table `yaml:"-"` // Loaded with the schema
+}
+
+// EnrichRule adds fields of the lookup row matching a column to the row,
+// for templates
+type EnrichRule struct {
+	Column string            `yaml:"column"` // Row column matched against the lookup
+	Lookup string            `yaml:"lookup"` // table.column
+	Fields map[string]string `yaml:"fields"` // New field -> lookup column
+}
+
+// ParseLookupRef splits a table.column lookup reference
+func ParseLookupRef(ref string) (table, column string, ok bool) {
+	table, column, ok = strings.Cut(ref, ".")
+	return table, column, ok && table != "" && column != ""
+}
+
+// loadLookups loads the reference tables of a schema and indexes the columns
+// that in_lookup and enrich refer to
+func loadLookups(schema *Schema, dir string) error {
+	for name, source := range schema.Lookups {
+		if source == nil || source.File == "" {
+			return fmt.Errorf("lookup '%s' needs a file", name)
+		}
+		path := source.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		sourceType := lookup.SourceType(path, source.Type)
+		if (sourceType == lookup.SourceSQLite) != (source.Query != "") {
+			return fmt.Errorf("query is required for sqlite lookups and only supported for them (lookup '%s')", name)
+		}
+
+		table, err := lookup.Load(path, sourceType, source.Query)
+		if err != nil {
+			return fmt.Errorf("failed to load lookup '%s': %w", name, err)
+		}
+		source.Table = table
+	}
+
+	for _, col := range schema.Columns {
+		if col.InLookup != "" {
+			if err := schema.indexLookup(col.InLookup); err != nil {
+				return fmt.Errorf("invalid in_lookup for column '%s': %w", col.Name, err)
+			}
+		}
+		if col.Items != nil && col.Items.InLookup != "" {
+			if err := schema.indexLookup(col.Items.InLookup); err != nil {
+				return fmt.Errorf("invalid in_lookup for items of column '%s': %w", col.Name, err)
+			}
+		}
+	}
+
+	fields := make(map[string]bool)
+	for _, rule := range schema.Enrich {
+		if schema.GetColumnByName(rule.Column) == nil {
+			return fmt.Errorf("enrich column '%s' is not a schema column", rule.Column)
+		}
+		if err := schema.indexLookup(rule.Lookup); err != nil {
+			return fmt.Errorf("invalid enrich lookup for column '%s': %w", rule.Column, err)
+		}
+		if len(rule.Fields) == 0 {
+			return fmt.Errorf("enrich of column '%s' has no fields", rule.Column)
+		}
+
+		tableName, _, _ := ParseLookupRef(rule.Lookup)
+		table := schema.Lookups[tableName].Table
+		for field, column := range rule.Fields {
+			if schema.GetColumnByName(field) != nil || slices.Contains(reservedFields, field) || fields[field] {
+				return fmt.Errorf("enrich field '%s' is already a column or field", field)
+			}
+			fields[field] = true
+			if !table.HasColumn(column) {
+				return fmt.Errorf("enrich field '%s': lookup '%s' has no column '%s'", field, tableName, column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// indexLookup checks a table.column reference and indexes the column
+func (s *Schema) indexLookup(ref string) error {
+	tableName, column, ok := ParseLookupRef(ref)
+	if !ok {
+		return fmt.Errorf("'%s' is not a table.column reference", ref)
+	}
+	source := s.Lookups[tableName]
+	if source == nil {
+		return fmt.Errorf("lookup '%s' is not defined", tableName)
+	}
+	if err := source.Table.BuildIndex(column); err != nil {
+		return fmt.Errorf("lookup '%s': %w", tableName, err)
+	}
+	return nil
+}
+
+// FindLookup returns the table and column of a table.column reference of the
+// schema; the column is indexed when the schema is loaded
+func (s *Schema) FindLookup(ref string) (*lookup.Table, string) {
+	tableName, column, _ := ParseLookupRef(ref)
+	return s.Lookups[tableName].Table, column
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MockConfig defines scripted responses of the dry-run mock server
+type MockConfig struct {
+	Seed      int64          `yaml:"seed,omitempty"`
+	Latency   MockLatency    `yaml:"latency,omitempty"`
+	Responses []MockResponse `yaml:"responses,omitempty"`
+	Errors    MockErrors     `yaml:"errors,omitempty"`
+	Rules     []MockRule     `yaml:"rules,omitempty"`
+}
+
+// MockLatency defines a uniformly distributed response latency
+type MockLatency struct {
+	Min time.Duration `yaml:"min,omitempty"`
+	Max time.Duration `yaml:"max,omitempty"`
+}
+
+// MockResponse is a weighted entry of the status distribution
+type MockResponse struct {
+	Status  int               `yaml:"status"`
+	Weight  float64           `yaml:"weight,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// MockErrors defines the rates of injected transport errors
+type MockErrors struct {
+	TimeoutRate float64 `yaml:"timeout_rate,omitempty"` // Never respond until the client gives up
+	ResetRate   float64 `yaml:"reset_rate,omitempty"`   // Close the connection without a response
+}
+
+// MockRule returns a fixed response for requests matching all conditions.
+// Match keys are "method", "path", "host", "query.<name>", "header.<name>" or
+// "body.<field>[.<field>...]" for JSON request bodies.
+type MockRule struct {
+	Match   map[string]string `yaml:"match"`
+	Status  int               `yaml:"status,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Latency *MockLatency      `yaml:"latency,omitempty"`
+	Error   string            `yaml:"error,omitempty"` // "timeout" or "reset"
+}
+
+// LoadMockConfig loads and parses a mock server script file
+func LoadMockConfig(filename string) (*MockConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock config file: %w", err)
+	}
+
+	var config MockConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse mock config YAML: %w", err)
+	}
+
+	if err := validateMockConfig(&config); err != nil {
+		return nil, fmt.Errorf("invalid mock config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// DefaultMockConfig returns a mock script that always answers with the first
+// success status and a body satisfying the success response keys
+func DefaultMockConfig(requestConfig *RequestConfig) (*MockConfig, error) {
+	body := "{}"
+	if len(requestConfig.Success.ResponseKeys) > 0 {
+		data, err := json.Marshal(requestConfig.Success.ResponseKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode mock response body: %w", err)
+		}
+		body = string(data)
+	}
+
+	return &MockConfig{
+		Responses: []MockResponse{
+			{Status: requestConfig.Success.StatusIn[0], Body: body},
+		},
+	}, nil
+}
+
+// validateMockConfig performs basic validation on the mock configuration
+func validateMockConfig(config *MockConfig) error {
+	if config.Latency.Max < config.Latency.Min {
+		return fmt.Errorf("latency max must not be less than min")
+	}
+
+	for i, response := range config.Responses {
+		if response.Status < 100 || response.Status > 599 {
+			return fmt.Errorf("invalid status %d in response %d", response.Status, i+1)
+		}
+		if response.Weight < 0 {
+			return fmt.Errorf("negative weight in response %d", i+1)
+		}
+	}
+
+	if config.Errors.TimeoutRate < 0 || config.Errors.ResetRate < 0 ||
+		config.Errors.TimeoutRate+config.Errors.ResetRate > 1 {
+		return fmt.Errorf("error rates must be between 0 and 1")
+	}
+
+	for i, rule := range config.Rules {
+		if len(rule.Match) == 0 {
+			return fmt.Errorf("rule %d has no match conditions", i+1)
+		}
+		switch rule.Error {
+		case "", "timeout", "reset":
+		default:
+			return fmt.Errorf("unsupported error '%s' in rule %d", rule.Error, i+1)
+		}
+		if rule.Error == "" && rule.Status != 0 && (rule.Status < 100 || rule.Status > 599) {
+			return fmt.Errorf("invalid status %d in rule %d", rule.Status, i+1)
+		}
+	}
+
+	return nil
+}
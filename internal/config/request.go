@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,6 +17,26 @@ type RequestConfig struct {
 	Proxy    string                 `yaml:"proxy,omitempty"`
 	Success  SuccessCondition       `yaml:"success"`
 	Timeout  string                 `yaml:"timeout,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"`
+	Schedule       *ScheduleConfig       `yaml:"schedule,omitempty"`
+}
+
+// Circuit breaker actions taken when a threshold is exceeded
+const (
+	BreakerActionAbort = "abort" // Stop dispatching rows and end the run
+	BreakerActionPause = "pause" // Stop dispatching until a half-open probe succeeds
+)
+
+// CircuitBreakerConfig stops or pauses a run when the API keeps failing
+type CircuitBreakerConfig struct {
+	FailureRate    float64 `yaml:"failure_rate,omitempty"`    // Trip when the failure percentage over the window exceeds this
+	Window         int     `yaml:"window,omitempty"`          // Number of recent requests for failure_rate (default 100)
+	Consecutive5xx int     `yaml:"consecutive_5xx,omitempty"` // Trip after this many 5xx responses in a row
+	OnTrip         string  `yaml:"on_trip,omitempty"`         // Action for the thresholds above: abort (default) or pause
+	PauseOn        []int   `yaml:"pause_on,omitempty"`        // Status codes that pause the run immediately (e.g. 401, 403)
+	Cooldown       string  `yaml:"cooldown,omitempty"`        // Wait before each half-open probe (default 30s)
+	MaxPause       string  `yaml:"max_pause,omitempty"`       // Abort if still paused after this long (default 30m)
 }
 
 // SuccessCondition defines conditions for successful requests
@@ -31,6 +52,11 @@ func LoadRequestConfig(filename string) (*RequestConfig, error) {
 		return nil, fmt.Errorf("failed to read request config file: %w", err)
 	}
 
+	return ParseRequestConfig(data)
+}
+
+// ParseRequestConfig parses and validates request config YAML (e.g. stored in a run manifest)
+func ParseRequestConfig(data []byte) (*RequestConfig, error) {
 	var config RequestConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse request config YAML: %w", err)
@@ -54,6 +80,18 @@ func validateRequestConfig(config *RequestConfig) error {
 		return fmt.Errorf("url is required")
 	}
 
+	if config.CircuitBreaker != nil {
+		if err := validateCircuitBreaker(config.CircuitBreaker); err != nil {
+			return fmt.Errorf("invalid circuit_breaker: %w", err)
+		}
+	}
+
+	if config.Schedule != nil {
+		if err := validateSchedule(config.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
 	if len(config.Success.StatusIn) == 0 {
 		// Default to 200-299 status codes
 		config.Success.StatusIn = []int{200, 201, 202, 203, 204, 205, 206, 207, 208, 226}
@@ -62,6 +100,42 @@ func validateRequestConfig(config *RequestConfig) error {
 	return nil
 }
 
+// validateCircuitBreaker validates the circuit breaker thresholds and fills defaults
+func validateCircuitBreaker(breaker *CircuitBreakerConfig) error {
+	if breaker.FailureRate < 0 || breaker.FailureRate > 100 {
+		return fmt.Errorf("failure_rate must be between 0 and 100")
+	}
+	if breaker.Window < 0 || breaker.Consecutive5xx < 0 {
+		return fmt.Errorf("window and consecutive_5xx must not be negative")
+	}
+	if breaker.Window == 0 {
+		breaker.Window = 100
+	}
+
+	switch breaker.OnTrip {
+	case "":
+		breaker.OnTrip = BreakerActionAbort
+	case BreakerActionAbort, BreakerActionPause:
+	default:
+		return fmt.Errorf("unsupported on_trip '%s'", breaker.OnTrip)
+	}
+
+	if breaker.Cooldown == "" {
+		breaker.Cooldown = "30s"
+	}
+	if _, err := time.ParseDuration(breaker.Cooldown); err != nil {
+		return fmt.Errorf("invalid cooldown: %w", err)
+	}
+	if breaker.MaxPause == "" {
+		breaker.MaxPause = "30m"
+	}
+	if _, err := time.ParseDuration(breaker.MaxPause); err != nil {
+		return fmt.Errorf("invalid max_pause: %w", err)
+	}
+
+	return nil
+}
+
 // IsSuccessStatus checks if the given status code is considered successful
 func (rc *RequestConfig) IsSuccessStatus(statusCode int) bool {
 	for _, code := range rc.Success.StatusIn {
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet holds scripted validation rules and transforms by name. It is the
+// rules section of a schema and the content of a rule file.
+type RuleSet struct {
+	Validators map[string]*ScriptRule `yaml:"validators,omitempty"`
+	Transforms map[string]*ScriptRule `yaml:"transforms,omitempty"`
+}
+
+// ScriptRule is a validation rule or transform written as an expression. The
+// expression sees the value as `value` and the options of the rule as `params`;
+// validators return a bool and transforms the new value as a string. Scripts
+// cannot reach files, the network or the process, so rule files can be shared
+// like schemas.
+type ScriptRule struct {
+	Expr    string `yaml:"expr"`
+	Message string `yaml:"message,omitempty"` // Validators: error when the value is rejected
+
+	Program *vm.Program `yaml:"-"` // Compiled when the schema is loaded
+}
+
+// ScriptEnv returns the variables scripted rules are run with
+func ScriptEnv(value string, params map[string]any) map[string]any {
+	if params == nil {
+		params = map[string]any{}
+	}
+	return map[string]any{"value": value, "params": params}
+}
+
+// Names of the rules registered in the validator package, so that schemas can
+// reference them
+var (
+	registeredMu         sync.RWMutex
+	registeredValidators = make(map[string]bool)
+	registeredTransforms = make(map[string]bool)
+)
+
+// RegisterValidationRuleName accepts a validation rule name in schemas; it is
+// called by validator.RegisterValidator
+func RegisterValidationRuleName(name string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredValidators[name] = true
+}
+
+// RegisterTransformName accepts a transform name in schemas; it is called by
+// validator.RegisterTransform
+func RegisterTransformName(name string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredTransforms[name] = true
+}
+
+// loadRuleFiles merges the rule files of a schema into its rules section
+func loadRuleFiles(schema *Schema, dir string) error {
+	for _, file := range schema.RuleFiles {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+
+		var rules RuleSet
+		if err := yaml.Unmarshal(data, &rules); err != nil {
+			return fmt.Errorf("failed to parse rule file %s: %w", file, err)
+		}
+
+		if err := mergeRules(&schema.Rules.Validators, rules.Validators, file); err != nil {
+			return err
+		}
+		if err := mergeRules(&schema.Rules.Transforms, rules.Transforms, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeRules adds the rules of a rule file, which may not redefine a rule
+func mergeRules(dst *map[string]*ScriptRule, src map[string]*ScriptRule, file string) error {
+	for name, rule := range src {
+		if *dst == nil {
+			*dst = make(map[string]*ScriptRule)
+		}
+		if _, exists := (*dst)[name]; exists {
+			return fmt.Errorf("rule '%s' of rule file %s is already defined", name, file)
+		}
+		(*dst)[name] = rule
+	}
+	return nil
+}
+
+// validateRuleSet checks the names of scripted rules and compiles them
+func validateRuleSet(rules *RuleSet) error {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	for name, rule := range rules.Validators {
+		if slices.Contains(ValidationRuleNames, name) || registeredValidators[name] {
+			return fmt.Errorf("scripted validation rule '%s' shadows a built-in or registered rule", name)
+		}
+		if err := compileScript(rule, expr.AsBool()); err != nil {
+			return fmt.Errorf("invalid scripted validation rule '%s': %w", name, err)
+		}
+	}
+
+	for name, rule := range rules.Transforms {
+		if registeredTransforms[name] {
+			return fmt.Errorf("scripted transform '%s' shadows a registered transform", name)
+		}
+		if err := compileScript(rule, expr.AsKind(reflect.String)); err != nil {
+			return fmt.Errorf("invalid scripted transform '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// compileScript compiles the expression of a scripted rule
+func compileScript(rule *ScriptRule, returns expr.Option) error {
+	if rule == nil || rule.Expr == "" {
+		return fmt.Errorf("expr is required")
+	}
+
+	program, err := expr.Compile(rule.Expr, expr.Env(ScriptEnv("", nil)), returns)
+	if err != nil {
+		return err
+	}
+	rule.Program = program
+	return nil
+}
+
+// hasValidator reports whether a custom validation rule is registered or scripted
+func (r *RuleSet) hasValidator(name string) bool {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return registeredValidators[name] || r.Validators[name] != nil
+}
+
+// hasTransform reports whether a custom transform is registered or scripted
+func (r *RuleSet) hasTransform(name string) bool {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return registeredTransforms[name] || r.Transforms[name] != nil
+}
+
+// validatorNames lists the validation rules a schema can reference
+func (r *RuleSet) validatorNames() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	names := slices.Clone(ValidationRuleNames)
+	var custom []string
+	for name := range registeredValidators {
+		custom = append(custom, name)
+	}
+	for name := range r.Validators {
+		custom = append(custom, name)
+	}
+	slices.Sort(custom)
+	return append(names, custom...)
+}
+
+// transformNames lists the custom transforms a schema can reference
+func (r *RuleSet) transformNames() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	var names []string
+	for name := range registeredTransforms {
+		names = append(names, name)
+	}
+	for name := range r.Transforms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
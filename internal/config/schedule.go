@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones such as Asia/Seoul must resolve on every platform
+)
+
+// ScheduleConfig restricts sending to time windows (e.g. nightly batch hours)
+type ScheduleConfig struct {
+	Timezone string           `yaml:"timezone,omitempty"` // IANA time zone (default: local time)
+	Windows  []ScheduleWindow `yaml:"windows"`
+}
+
+// ScheduleWindow is a daily time window in which requests may be sent
+type ScheduleWindow struct {
+	Start string   `yaml:"start"`          // "HH:MM"
+	End   string   `yaml:"end"`            // "HH:MM"; earlier than start for windows crossing midnight
+	Rate  float64  `yaml:"rate,omitempty"` // Requests per second inside the window (default: --rate)
+	Days  []string `yaml:"days,omitempty"` // Days the window starts on (mon..sun, default: every day)
+}
+
+// weekdays maps day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Location returns the time zone of the schedule
+func (s *ScheduleConfig) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// ParseClock parses "HH:MM" into minutes after midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s' (expected HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseWeekday parses a day name such as "mon" or "monday"
+func ParseWeekday(value string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	for short, day := range weekdays {
+		if name == short || name == strings.ToLower(day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day '%s' (expected mon..sun or monday..sunday)", value)
+}
+
+// validateSchedule performs basic validation on the schedule
+func validateSchedule(schedule *ScheduleConfig) error {
+	if _, err := schedule.Location(); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	if len(schedule.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+
+	for i, window := range schedule.Windows {
+		start, err := ParseClock(window.Start)
+		if err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+		end, err := ParseClock(window.End)
+		if err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+		if start == end {
+			return fmt.Errorf("window %d: start and end must differ", i+1)
+		}
+		if window.Rate < 0 {
+			return fmt.Errorf("window %d: rate must not be negative", i+1)
+		}
+		for _, day := range window.Days {
+			if _, err := ParseWeekday(day); err != nil {
+				return fmt.Errorf("window %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
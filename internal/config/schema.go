@@ -3,39 +3,79 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata" // Timezones of datetime columns work without system zoneinfo
 
 	"github.com/shopspring/decimal"
 	"gopkg.in/yaml.v3"
+
+	"csvfire/internal/phone"
+)
+
+// Metadata columns appended to failed row exports after the schema columns
+const (
+	FailureReasonColumn    = "failure_reason"
+	FailureDetailColumn    = "failure_detail"
+	SourceRowColumn        = "source_row"
+	AttemptsColumn         = "attempts"
+	NormalizedColumnPrefix = "normalized_"
+	ComputedColumnPrefix   = "computed_"
 )
 
+// IsFailedRowMetaColumn reports whether a column is failed row export metadata
+func IsFailedRowMetaColumn(name string) bool {
+	switch name {
+	case FailureReasonColumn, FailureDetailColumn, SourceRowColumn, AttemptsColumn:
+		return true
+	}
+	return strings.HasPrefix(name, NormalizedColumnPrefix) || strings.HasPrefix(name, ComputedColumnPrefix)
+}
+
 // Schema represents the validation schema for CSV data
 type Schema struct {
-	Version     int                    `yaml:"version"`
-	Columns     []ColumnSchema         `yaml:"columns"`
-	RowRules    []RowRule              `yaml:"row_rules"`
-	Uniqueness  []UniquenessRule       `yaml:"uniqueness"`
-	NullPolicy  NullPolicy             `yaml:"null_policy"`
+	Version    int                      `yaml:"version"`
+	Columns    []ColumnSchema           `yaml:"columns"`
+	RowRules   []RowRule                `yaml:"row_rules"`
+	Uniqueness []UniquenessRule         `yaml:"uniqueness"`
+	NullPolicy NullPolicy               `yaml:"null_policy"`
+	Rules      RuleSet                  `yaml:"rules,omitempty"`      // Scripted rules referenced by name
+	RuleFiles  []string                 `yaml:"rule_files,omitempty"` // Files of scripted rules, relative to the schema
+	Lookups    map[string]*LookupSource `yaml:"lookups,omitempty"`    // Reference tables by name
+	Enrich     []EnrichRule             `yaml:"enrich,omitempty"`     // Fields added from lookups
+	Computed   []ComputedColumn         `yaml:"computed,omitempty"`   // Columns derived from the row
 }
 
 // ColumnSchema defines validation rules for a single column
 type ColumnSchema struct {
-	Name        string              `yaml:"name"`
-	Type        string              `yaml:"type"`
-	Required    bool                `yaml:"required"`
-	Secret      bool                `yaml:"secret"`
-	MinLen      *int                `yaml:"min_len,omitempty"`
-	MaxLen      *int                `yaml:"max_len,omitempty"`
-	Regex       string              `yaml:"regex,omitempty"`
-	Enum        []string            `yaml:"enum,omitempty"`
-	Range       *RangeRule          `yaml:"range,omitempty"`
-	Format      string              `yaml:"format,omitempty"`
-	Preprocess  []PreprocessRule    `yaml:"preprocess,omitempty"`
-	Validators  []ValidationRule    `yaml:"validators,omitempty"`
-	Transform   []TransformRule     `yaml:"transform,omitempty"`
-	Normalize   *NormalizeRule      `yaml:"normalize,omitempty"`
+	Name        string           `yaml:"name"`
+	Type        string           `yaml:"type"`
+	Required    bool             `yaml:"required"`
+	RequiredIf  *Condition       `yaml:"required_if,omitempty"`  // Required when the condition holds
+	ForbiddenIf *Condition       `yaml:"forbidden_if,omitempty"` // Must be empty when the condition holds
+	Compare     []CompareRule    `yaml:"compare,omitempty"`      // Constraints relative to other columns
+	InLookup    string           `yaml:"in_lookup,omitempty"`    // table.column of a lookup that must hold the value
+	Secret      bool             `yaml:"secret"`
+	MinLen      *int             `yaml:"min_len,omitempty"`
+	MaxLen      *int             `yaml:"max_len,omitempty"`
+	Regex       string           `yaml:"regex,omitempty"`
+	Enum        []string         `yaml:"enum,omitempty"`
+	Range       *RangeRule       `yaml:"range,omitempty"`
+	Format      string           `yaml:"format,omitempty"`
+	Timezone    string           `yaml:"timezone,omitempty"`  // datetime: zone of values without an offset, and of the normalized value
+	Location    *time.Location   `yaml:"-"`                   // datetime: the loaded timezone (UTC if not set)
+	Delimiter   string           `yaml:"delimiter,omitempty"` // list: item separator (default ",")
+	Items       *ColumnSchema    `yaml:"items,omitempty"`     // list: rules applied to each item
+	MinItems    *int             `yaml:"min_items,omitempty"`
+	MaxItems    *int             `yaml:"max_items,omitempty"`
+	Preprocess  []PreprocessRule `yaml:"preprocess,omitempty"`
+	Validators  []ValidationRule `yaml:"validators,omitempty"`
+	Transform   []TransformRule  `yaml:"transform,omitempty"`
+	Normalize   *NormalizeRule   `yaml:"normalize,omitempty"`
 }
 
 // RangeRule defines min/max constraints
@@ -54,12 +94,58 @@ type PreprocessRule struct {
 // ValidationRule defines custom validation
 type ValidationRule struct {
 	Regex   string `yaml:"regex,omitempty"`
+	Rule    string `yaml:"rule,omitempty"` // Named rule, see ValidationRuleNames, or a custom rule
 	Message string `yaml:"message,omitempty"`
+
+	// Custom rules: options passed to the registered function or script
+	Params map[string]any `yaml:"params,omitempty"`
+
+	// kr_bank_account: bank code of the account, fixed or read from another column
+	Bank       string `yaml:"bank,omitempty"`
+	BankColumn string `yaml:"bank_column,omitempty"`
+
+	// phone: region of numbers without a country code, allowed regions and number type
+	DefaultRegion string   `yaml:"default_region,omitempty"`
+	Regions       []string `yaml:"regions,omitempty"`
+	PhoneType     string   `yaml:"phone_type,omitempty"`
+}
+
+// ValidationRuleNames lists the named validation rules
+var ValidationRuleNames = []string{
+	"rrn",             // 주민등록번호
+	"frn",             // 외국인등록번호
+	"rrn_or_frn",      // 주민등록번호 or 외국인등록번호
+	"brn",             // 사업자등록번호
+	"crn",             // 법인등록번호
+	"kr_phone",        // Any Korean phone number
+	"kr_mobile",       // Korean mobile number
+	"kr_landline",     // Korean landline number with an area code
+	"kr_postcode",     // 5-digit 우편번호
+	"kr_bank_account", // Bank account number, checked against the bank code
+	"phone",           // Phone number of any supported region
 }
 
+// Phone number types accepted by phone_type
+var phoneTypes = []string{phone.Mobile, phone.Landline, phone.VoIP, phone.TollFree, phone.Business}
+
 // TransformRule defines transformation operations
 type TransformRule struct {
-	FormatKoreanPhoneE164 bool `yaml:"format_korean_phone_e164,omitempty"`
+	Rule                  string           `yaml:"rule,omitempty"`   // Custom transform, registered or scripted
+	Params                map[string]any   `yaml:"params,omitempty"` // Options of the custom transform
+	PhoneE164             *PhoneFormatRule `yaml:"phone_e164,omitempty"`
+	FormatKoreanPhoneE164 bool             `yaml:"format_korean_phone_e164,omitempty"`
+	FormatKoreanPhone     bool             `yaml:"format_korean_phone,omitempty"`    // 010-1234-5678, 02-123-4567
+	FormatRRN             bool             `yaml:"format_rrn,omitempty"`             // 900101-1234567 (also 외국인등록번호)
+	MaskRRN               bool             `yaml:"mask_rrn,omitempty"`               // 900101-1******
+	FormatBRN             bool             `yaml:"format_brn,omitempty"`             // 123-45-67890
+	FormatCRN             bool             `yaml:"format_crn,omitempty"`             // 110111-1234567
+	NormalizeRoadAddress  bool             `yaml:"normalize_road_address,omitempty"` // 도로명주소 spacing and region names
+}
+
+// PhoneFormatRule formats phone numbers of any supported region
+type PhoneFormatRule struct {
+	DefaultRegion string `yaml:"default_region,omitempty"` // Region of numbers without a country code
+	Format        string `yaml:"format,omitempty"`         // e164 (default), international or national
 }
 
 // NormalizeRule defines normalization mappings
@@ -90,16 +176,52 @@ func LoadSchema(filename string) (*Schema, error) {
 		return nil, fmt.Errorf("failed to read schema file: %w", err)
 	}
 
+	return ParseSchema(data, filepath.Dir(filename))
+}
+
+// SchemaFiles returns the rule files and lookup tables a schema file refers
+// to, as written in the schema, without reading them
+func SchemaFiles(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema YAML: %w", err)
+	}
+
+	files := append([]string{}, schema.RuleFiles...)
+	for _, source := range schema.Lookups {
+		if source != nil {
+			files = append(files, source.File)
+		}
+	}
+	return files, nil
+}
+
+// ParseSchema parses and validates schema YAML (e.g. stored in a run manifest);
+// rule files are read relative to dir
+func ParseSchema(data []byte, dir string) (*Schema, error) {
 	var schema Schema
 	if err := yaml.Unmarshal(data, &schema); err != nil {
 		return nil, fmt.Errorf("failed to parse schema YAML: %w", err)
 	}
 
+	if err := loadRuleFiles(&schema, dir); err != nil {
+		return nil, err
+	}
+
 	// Validate schema
 	if err := validateSchema(&schema); err != nil {
 		return nil, fmt.Errorf("invalid schema: %w", err)
 	}
 
+	if err := loadLookups(&schema, dir); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
 	return &schema, nil
 }
 
@@ -115,7 +237,8 @@ func validateSchema(schema *Schema) error {
 
 	// Validate column names are unique
 	seen := make(map[string]bool)
-	for _, col := range schema.Columns {
+	for i := range schema.Columns {
+		col := &schema.Columns[i]
 		if col.Name == "" {
 			return fmt.Errorf("column name cannot be empty")
 		}
@@ -124,26 +247,140 @@ func validateSchema(schema *Schema) error {
 		}
 		seen[col.Name] = true
 
-		// Validate column type
-		if !isValidColumnType(col.Type) {
-			return fmt.Errorf("invalid column type '%s' for column '%s'", col.Type, col.Name)
+		if err := validateColumn(col, col.Name, &schema.Rules); err != nil {
+			return err
 		}
+	}
 
-		// Validate regex if present
-		if col.Regex != "" {
-			if _, err := regexp.Compile(col.Regex); err != nil {
-				return fmt.Errorf("invalid regex for column '%s': %w", col.Name, err)
+	if err := validateRuleSet(&schema.Rules); err != nil {
+		return err
+	}
+
+	if err := validateConditions(schema); err != nil {
+		return err
+	}
+
+	if err := validateComputed(schema); err != nil {
+		return err
+	}
+
+	// Bank codes of account columns must come from a schema column
+	for _, col := range schema.Columns {
+		for _, rule := range col.Validators {
+			if rule.BankColumn != "" && !seen[rule.BankColumn] {
+				return fmt.Errorf("bank_column '%s' of column '%s' is not a schema column", rule.BankColumn, col.Name)
 			}
 		}
+	}
 
-		// Validate validation rules
-		for _, rule := range col.Validators {
-			if rule.Regex != "" {
-				if _, err := regexp.Compile(rule.Regex); err != nil {
-					return fmt.Errorf("invalid regex in validation rule for column '%s': %w", col.Name, err)
-				}
+	return nil
+}
+
+// validateColumn validates the type and rules of a column, or of the items of
+// a list column (name identifies the column in errors)
+func validateColumn(col *ColumnSchema, name string, rules *RuleSet) error {
+	// Validate column type
+	if !isValidColumnType(col.Type) {
+		return fmt.Errorf("invalid column type '%s' for column '%s'", col.Type, name)
+	}
+
+	// Validate regex if present
+	if col.Regex != "" {
+		if _, err := regexp.Compile(col.Regex); err != nil {
+			return fmt.Errorf("invalid regex for column '%s': %w", name, err)
+		}
+	}
+
+	// Validate validation rules
+	for _, rule := range col.Validators {
+		if rule.Regex != "" {
+			if _, err := regexp.Compile(rule.Regex); err != nil {
+				return fmt.Errorf("invalid regex in validation rule for column '%s': %w", name, err)
 			}
 		}
+		if rule.Rule != "" && !slices.Contains(ValidationRuleNames, rule.Rule) && !rules.hasValidator(rule.Rule) {
+			return fmt.Errorf("unknown validation rule '%s' for column '%s' (available: %s)",
+				rule.Rule, name, strings.Join(rules.validatorNames(), ", "))
+		}
+		if len(rule.Params) > 0 && (rule.Rule == "" || slices.Contains(ValidationRuleNames, rule.Rule)) {
+			return fmt.Errorf("params are only supported for custom validation rules (column '%s')", name)
+		}
+		if (rule.Bank != "" || rule.BankColumn != "") && rule.Rule != "kr_bank_account" {
+			return fmt.Errorf("bank and bank_column are only supported for the kr_bank_account rule (column '%s')", name)
+		}
+		if rule.Rule == "kr_bank_account" && (rule.Bank == "") == (rule.BankColumn == "") {
+			return fmt.Errorf("kr_bank_account needs either bank or bank_column (column '%s')", name)
+		}
+		if (rule.DefaultRegion != "" || len(rule.Regions) > 0 || rule.PhoneType != "") && rule.Rule != "phone" {
+			return fmt.Errorf("default_region, regions and phone_type are only supported for the phone rule (column '%s')", name)
+		}
+		for _, region := range append([]string{rule.DefaultRegion}, rule.Regions...) {
+			if region != "" && !phone.IsSupportedRegion(region) {
+				return fmt.Errorf("unsupported phone region '%s' for column '%s' (available: %s)",
+					region, name, strings.Join(phone.Regions(), ", "))
+			}
+		}
+		if rule.PhoneType != "" && !slices.Contains(phoneTypes, rule.PhoneType) {
+			return fmt.Errorf("invalid phone_type '%s' for column '%s' (available: %s)",
+				rule.PhoneType, name, strings.Join(phoneTypes, ", "))
+		}
+	}
+
+	for _, rule := range col.Transform {
+		if rule.Rule != "" && !rules.hasTransform(rule.Rule) {
+			return fmt.Errorf("unknown transform '%s' for column '%s' (available: %s)",
+				rule.Rule, name, strings.Join(rules.transformNames(), ", "))
+		}
+		if len(rule.Params) > 0 && rule.Rule == "" {
+			return fmt.Errorf("params are only supported for custom transforms (column '%s')", name)
+		}
+		if rule.PhoneE164 == nil {
+			continue
+		}
+		if rule.PhoneE164.DefaultRegion != "" && !phone.IsSupportedRegion(rule.PhoneE164.DefaultRegion) {
+			return fmt.Errorf("unsupported phone region '%s' for column '%s' (available: %s)",
+				rule.PhoneE164.DefaultRegion, name, strings.Join(phone.Regions(), ", "))
+		}
+		if rule.PhoneE164.Format != "" && !phone.IsValidFormat(rule.PhoneE164.Format) {
+			return fmt.Errorf("invalid phone format '%s' for column '%s' (e164, international, national)",
+				rule.PhoneE164.Format, name)
+		}
+	}
+
+	if col.Timezone != "" && col.Type != "datetime" {
+		return fmt.Errorf("timezone is only supported for datetime columns (column '%s')", name)
+	}
+	if col.Type == "datetime" {
+		col.Location = time.UTC
+		if col.Timezone != "" {
+			location, err := time.LoadLocation(col.Timezone)
+			if err != nil {
+				return fmt.Errorf("invalid timezone for column '%s': %w", name, err)
+			}
+			col.Location = location
+		}
+	}
+
+	if col.Type == "list" {
+		if len(col.Enum) > 0 || col.MinLen != nil || col.MaxLen != nil {
+			return fmt.Errorf("enum, min_len and max_len of list columns belong under items (column '%s')", name)
+		}
+		if col.Items != nil {
+			if col.Items.Type == "" {
+				col.Items.Type = "string"
+			}
+			if col.Items.Type == "list" {
+				return fmt.Errorf("list items cannot be lists (column '%s')", name)
+			}
+			if col.Items.RequiredIf != nil || col.Items.ForbiddenIf != nil || len(col.Items.Compare) > 0 {
+				return fmt.Errorf("required_if, forbidden_if and compare are not supported for list items (column '%s')", name)
+			}
+			if err := validateColumn(col.Items, name+" items", rules); err != nil {
+				return err
+			}
+		}
+	} else if col.Items != nil || col.Delimiter != "" || col.MinItems != nil || col.MaxItems != nil {
+		return fmt.Errorf("items, delimiter, min_items and max_items are only supported for list columns (column '%s')", name)
 	}
 
 	return nil
@@ -160,6 +397,10 @@ func isValidColumnType(colType string) bool {
 		return true
 	case strings.HasPrefix(colType, "decimal("):
 		return isValidDecimalType(colType)
+	case colType == "bool", colType == "email", colType == "uuid", colType == "json", colType == "list":
+		return true
+	case colType == "datetime":
+		return true
 	case strings.HasPrefix(colType, "date"):
 		return true
 	default:
@@ -195,6 +436,15 @@ func (s *Schema) GetColumnByName(name string) *ColumnSchema {
 	return nil
 }
 
+// GetComputedNames returns the computed column names in order
+func (s *Schema) GetComputedNames() []string {
+	names := make([]string, len(s.Computed))
+	for i, col := range s.Computed {
+		names[i] = col.Name
+	}
+	return names
+}
+
 // GetColumnNames returns all column names in order
 func (s *Schema) GetColumnNames() []string {
 	names := make([]string, len(s.Columns))
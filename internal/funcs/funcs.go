@@ -0,0 +1,267 @@
+// Package funcs provides the functions of request templates and computed
+// column templates. They accept typed row values as well as strings.
+package funcs
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"csvfire/internal/phone"
+)
+
+// Map returns the template functions
+func Map() template.FuncMap {
+	return template.FuncMap{
+		"dateFormat":  dateFormat,
+		"addDays":     addDays,
+		"addMonths":   addMonths,
+		"addYears":    addYears,
+		"fixed":       fixed,
+		"json":        toJSON,
+		"default":     defaultValue,
+		"toE164KR":    toE164KR,
+		"phoneE164":   phoneE164,
+		"phoneFormat": phoneFormat,
+		"phoneType":   phoneType,
+		"phoneKind":   func(value any) string { return KoreanPhoneKind(toString(value)) },
+		"maskRRN":     func(value any) string { return MaskRRN(toString(value)) },
+		"mask":        mask,
+		"hash":        hash,
+		"upper":       func(value any) string { return strings.ToUpper(toString(value)) },
+		"lower":       func(value any) string { return strings.ToLower(toString(value)) },
+		"trim":        func(value any) string { return strings.TrimSpace(toString(value)) },
+	}
+}
+
+// KoreanPhoneKind classifies a Korean phone number as phone.Mobile,
+// phone.Landline, phone.VoIP or phone.Business; it returns "" if the value is
+// not a valid Korean phone number
+func KoreanPhoneKind(value string) string {
+	number, err := phone.Parse(value, "KR")
+	if err != nil || number.Region != "KR" {
+		return ""
+	}
+	return number.Type
+}
+
+// MaskRRN masks the last six digits of a resident or foreigner number,
+// keeping the birth date and gender digit: 900101-1******
+func MaskRRN(value string) string {
+	digits := strings.NewReplacer("-", "", " ", "").Replace(value)
+	if len(digits) != 13 || strings.Trim(digits, "0123456789") != "" {
+		return value
+	}
+	return digits[:6] + "-" + digits[6:7] + "******"
+}
+
+// toString formats a row value as text. Dates without a time of day are
+// formatted as YYYY-MM-DD, lists as comma-separated items, JSON objects as
+// JSON and nulls as an empty string.
+func toString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = toString(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		encoded, err := toJSON(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return encoded
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case decimal.Decimal:
+		return v.String()
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toTime converts a date value or a date string to a time
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		// Try parsing common formats
+		formats := []string{
+			"20060102",   // YYYYMMDD
+			"2006-01-02", // YYYY-MM-DD
+			"01/02/2006", // MM/DD/YYYY
+			"02/01/2006", // DD/MM/YYYY
+			time.RFC3339,
+		}
+		for _, format := range formats {
+			if parsed, err := time.Parse(format, v); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// dateFormat formats a date according to the given layout; values that are
+// not dates are returned as they are
+func dateFormat(layout string, value any) string {
+	date, ok := toTime(value)
+	if !ok {
+		return toString(value)
+	}
+	return date.Format(layout)
+}
+
+// addDays adds days to a date; nulls stay null
+func addDays(days int, value any) (any, error) {
+	return shiftDate(value, 0, 0, days)
+}
+
+// addMonths adds months to a date; nulls stay null
+func addMonths(months int, value any) (any, error) {
+	return shiftDate(value, 0, months, 0)
+}
+
+// addYears adds years to a date; nulls stay null
+func addYears(years int, value any) (any, error) {
+	return shiftDate(value, years, 0, 0)
+}
+
+// shiftDate adds a period to a date value
+func shiftDate(value any, years, months, days int) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	date, ok := toTime(value)
+	if !ok {
+		return nil, fmt.Errorf("not a date: %v", value)
+	}
+	return date.AddDate(years, months, days), nil
+}
+
+// fixed formats a number with the given number of decimal places
+func fixed(places int, value any) (string, error) {
+	var d decimal.Decimal
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case int64:
+		d = decimal.NewFromInt(v)
+	case float64:
+		d = decimal.NewFromFloat(v)
+	case decimal.Decimal:
+		d = v
+	case string:
+		parsed, err := decimal.NewFromString(v)
+		if err != nil {
+			return "", fmt.Errorf("not a number: %q", v)
+		}
+		d = parsed
+	default:
+		return "", fmt.Errorf("not a number: %v", value)
+	}
+	return d.StringFixed(int32(places)), nil
+}
+
+// toJSON formats a value as a JSON literal: numbers unquoted, dates and
+// strings quoted, and null for nulls
+func toJSON(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool, int64, float64, decimal.Decimal:
+		return toString(v), nil
+	case time.Time:
+		value = toString(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			encoded, err := toJSON(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = encoded
+		}
+		return "[" + strings.Join(items, ",") + "]", nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// defaultValue returns value, or fallback if value is null or empty
+func defaultValue(fallback, value any) any {
+	if value == nil || value == "" {
+		return fallback
+	}
+	return value
+}
+
+// toE164KR converts Korean phone numbers to E164 format; values that are not
+// valid phone numbers are returned as they are
+func toE164KR(value any) string {
+	return phoneFormat(phone.FormatE164, "KR", value)
+}
+
+// phoneFormat formats a phone number (e164, international or national);
+// values that are not valid phone numbers are returned as they are
+func phoneFormat(format, defaultRegion string, value any) string {
+	number, err := phone.Parse(toString(value), defaultRegion)
+	if err != nil {
+		return toString(value)
+	}
+	return number.Format(format)
+}
+
+// phoneE164 formats a phone number in E.164 form
+func phoneE164(defaultRegion string, value any) string {
+	return phoneFormat(phone.FormatE164, defaultRegion, value)
+}
+
+// phoneType returns the type of a phone number (mobile, landline, ...) or ""
+// if it is not a valid phone number
+func phoneType(defaultRegion string, value any) string {
+	number, err := phone.Parse(toString(value), defaultRegion)
+	if err != nil {
+		return ""
+	}
+	return number.Type
+}
+
+// mask masks sensitive data
+func mask(value any) string {
+	s := toString(value)
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:2] + strings.Repeat("*", len(s)-4) + s[len(s)-2:]
+}
+
+// hash creates a SHA256 hash of the value
+func hash(value any) string {
+	h := sha256.Sum256([]byte(toString(value)))
+	return fmt.Sprintf("%x", h)
+}
@@ -0,0 +1,64 @@
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CheckpointFile lists the request hashes of rows that were sent successfully
+const CheckpointFile = "checkpoints.txt"
+
+// LoadCheckpoints reads the checkpoints of a run directory.
+// A missing file yields no checkpoints.
+func LoadCheckpoints(runDir string) (map[string]bool, error) {
+	checkpoints := make(map[string]bool)
+
+	file, err := os.Open(filepath.Join(runDir, CheckpointFile))
+	if os.IsNotExist(err) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoints: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if hash := strings.TrimSpace(scanner.Text()); hash != "" {
+			checkpoints[hash] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// SaveCheckpoints writes the checkpoints of a run directory, replacing it atomically
+func SaveCheckpoints(runDir string, checkpoints map[string]bool) error {
+	hashes := make([]string, 0, len(checkpoints))
+	for hash := range checkpoints {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	filename := filepath.Join(runDir, CheckpointFile)
+	tmpFile := filename + ".tmp"
+	data := strings.Join(hashes, "\n")
+	if len(hashes) > 0 {
+		data += "\n"
+	}
+	if err := os.WriteFile(tmpFile, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	if err := os.Rename(tmpFile, filename); err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"csvfire/internal/config"
+)
+
+// ManifestFile is the name of the run manifest inside a run directory
+const ManifestFile = "run.json"
+
+// manifestVersion is the current manifest format version
+const manifestVersion = 1
+
+// Manifest records everything needed to re-execute rows of a run.
+// Configs are stored by content so later edits to the files don't change retries.
+type Manifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	CSVFile     string    `json:"csv_file"`
+	CSVHash     string    `json:"csv_sha256"`
+	SchemaFile  string    `json:"schema_file"`
+	RequestFile string    `json:"request_file"`
+	Schema      string    `json:"schema"`
+	Request     string    `json:"request"`
+	Options     Options   `json:"options"`
+	Attempts    []Attempt `json:"attempts"`
+}
+
+// Options are the execution options of the original run
+type Options struct {
+	Concurrency int    `json:"concurrency"`
+	RateLimit   string `json:"rate_limit,omitempty"`
+	Timeout     string `json:"timeout"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+	MockFile    string `json:"mock_file,omitempty"`
+	Shard       string `json:"shard,omitempty"`     // "2/5" when the run sent one shard of the CSV
+	ShardKey    string `json:"shard_key,omitempty"` // Column hashed to assign rows to shards
+}
+
+// Attempt records a single execution (the initial run or a retry) of a run
+type Attempt struct {
+	Command     string    `json:"command"`
+	Filter      string    `json:"filter,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	TotalRows   int       `json:"total_rows"`
+	SuccessRows int       `json:"success_rows"`
+	FailedRows  int       `json:"failed_rows"`
+	SkippedRows int       `json:"skipped_rows"`
+
+	NotAttempted int    `json:"not_attempted_rows,omitempty"` // Rows read but not sent because the run was stopped
+	AbortReason  string `json:"abort_reason,omitempty"`
+}
+
+// NewManifest creates a manifest capturing the given config files and CSV
+func NewManifest(schemaFile, requestFile, csvFile string, options Options) (*Manifest, error) {
+	schemaData, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	requestData, err := os.ReadFile(requestFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request config file: %w", err)
+	}
+
+	csvHash, err := hashFile(csvFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		Version:     manifestVersion,
+		CreatedAt:   time.Now(),
+		CSVFile:     absPath(csvFile),
+		CSVHash:     csvHash,
+		SchemaFile:  absPath(schemaFile),
+		RequestFile: absPath(requestFile),
+		Schema:      string(schemaData),
+		Request:     string(requestData),
+		Options:     options,
+	}, nil
+}
+
+// LoadManifest reads the manifest of a run directory
+func LoadManifest(runDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(runDir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse run manifest: %w", err)
+	}
+
+	if manifest.Version > manifestVersion {
+		return nil, fmt.Errorf("unsupported run manifest version %d", manifest.Version)
+	}
+
+	return &manifest, nil
+}
+
+// Save writes the manifest into a run directory, replacing it atomically
+func (m *Manifest) Save(runDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run manifest: %w", err)
+	}
+
+	filename := filepath.Join(runDir, ManifestFile)
+	tmpFile := filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	if err := os.Rename(tmpFile, filename); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+
+	return nil
+}
+
+// AddAttempt appends an execution to the run history
+func (m *Manifest) AddAttempt(attempt Attempt) {
+	m.Attempts = append(m.Attempts, attempt)
+}
+
+// LoadConfigs parses the schema and request configs stored in the manifest;
+// rule files of the schema are read next to the original schema file
+func (m *Manifest) LoadConfigs() (*config.Schema, *config.RequestConfig, error) {
+	schema, err := config.ParseSchema([]byte(m.Schema), filepath.Dir(m.SchemaFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stored schema: %w", err)
+	}
+
+	requestConfig, err := config.ParseRequestConfig([]byte(m.Request))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stored request config: %w", err)
+	}
+
+	return schema, requestConfig, nil
+}
+
+// VerifyCSV checks that the CSV file still has the content of the original run,
+// since rows are identified by their position in the file
+func (m *Manifest) VerifyCSV() error {
+	csvHash, err := hashFile(m.CSVFile)
+	if err != nil {
+		return err
+	}
+	if csvHash != m.CSVHash {
+		return fmt.Errorf("CSV file %s changed since the original run", m.CSVFile)
+	}
+	return nil
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash CSV file: %w", err)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// absPath returns the absolute form of a path, or the path itself on error
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,246 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MergedLogFiles are the CSV logs of a run directory combined by MergeRuns
+var MergedLogFiles = []string{SentLogFile, "request_errors.csv", "validate_errors.csv", "replay_mismatches.csv"}
+
+// MergeResult summarizes a merge of run directories
+type MergeResult struct {
+	Runs          int
+	Shards        []string // Shards found in the run directories (e.g. "2/5")
+	MissingShards []string // Shards of the same count not among the merged runs
+	Rows          map[string]int
+	Checkpoints   int
+}
+
+// MergeRuns combines the run directories of shards of the same CSV into outDir.
+// Logs are concatenated, checkpoints are united and the manifests' attempts
+// are combined, so report and retry-failed work on the merged directory.
+// Row numbers and request IDs refer to the shared CSV and stay unique.
+func MergeRuns(outDir string, runDirs []string) (*MergeResult, error) {
+	if len(runDirs) == 0 {
+		return nil, fmt.Errorf("no run directories to merge")
+	}
+
+	manifests := make([]*Manifest, len(runDirs))
+	for i, runDir := range runDirs {
+		if absPath(runDir) == absPath(outDir) {
+			return nil, fmt.Errorf("output directory %s is one of the merged runs", outDir)
+		}
+		manifest, err := LoadManifest(runDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", runDir, err)
+		}
+		manifests[i] = manifest
+	}
+
+	// Rows are identified by their position in the CSV, so every shard must
+	// have read the same file with the same configuration
+	first := manifests[0]
+	result := &MergeResult{Runs: len(runDirs), Rows: make(map[string]int)}
+	seenShards := make(map[string]string)
+	shardCount := 0
+	for i, manifest := range manifests {
+		if manifest.CSVHash != first.CSVHash {
+			return nil, fmt.Errorf("%s ran a different CSV file than %s", runDirs[i], runDirs[0])
+		}
+		if manifest.Request != first.Request || manifest.Schema != first.Schema {
+			return nil, fmt.Errorf("%s used a different schema or request config than %s", runDirs[i], runDirs[0])
+		}
+
+		shard := manifest.Options.Shard
+		if shard == "" {
+			continue
+		}
+		if previous, ok := seenShards[shard]; ok {
+			return nil, fmt.Errorf("shard %s appears in both %s and %s", shard, previous, runDirs[i])
+		}
+		var index, count int
+		if _, err := fmt.Sscanf(shard, "%d/%d", &index, &count); err != nil {
+			return nil, fmt.Errorf("%s has an invalid shard '%s'", runDirs[i], shard)
+		}
+		if shardCount != 0 && count != shardCount {
+			return nil, fmt.Errorf("%s was split into %d shards, other runs into %d", runDirs[i], count, shardCount)
+		}
+		shardCount = count
+		seenShards[shard] = runDirs[i]
+		result.Shards = append(result.Shards, shard)
+	}
+	sort.Strings(result.Shards)
+	result.MissingShards = missingShards(result.Shards, shardCount)
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, name := range MergedLogFiles {
+		rows, err := concatCSV(filepath.Join(outDir, name), runDirs, name)
+		if err != nil {
+			return nil, err
+		}
+		if rows >= 0 {
+			result.Rows[name] = rows
+		} else if err := os.Remove(filepath.Join(outDir, name)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale %s: %w", name, err)
+		}
+	}
+
+	checkpoints := make(map[string]bool)
+	for _, runDir := range runDirs {
+		shardCheckpoints, err := LoadCheckpoints(runDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", runDir, err)
+		}
+		for hash := range shardCheckpoints {
+			checkpoints[hash] = true
+		}
+	}
+	if err := SaveCheckpoints(outDir, checkpoints); err != nil {
+		return nil, err
+	}
+	result.Checkpoints = len(checkpoints)
+
+	merged := *first
+	merged.CreatedAt = time.Now()
+	merged.Options.Shard = ""
+	merged.Options.ShardKey = ""
+	merged.Attempts = nil
+	for _, manifest := range manifests {
+		merged.Attempts = append(merged.Attempts, manifest.Attempts...)
+	}
+	sort.SliceStable(merged.Attempts, func(i, j int) bool {
+		return merged.Attempts[i].StartedAt.Before(merged.Attempts[j].StartedAt)
+	})
+	if err := merged.Save(outDir); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MergeFailedRows concatenates failed row exports of shards into one file and
+// returns the number of rows written
+func MergeFailedRows(outFile string, files []string) (int, error) {
+	if len(files) == 0 {
+		return 0, nil
+	}
+	if dir := filepath.Dir(outFile); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	rows, err := concatCSV(outFile, files, "")
+	if rows < 0 {
+		rows = 0
+	}
+	return rows, err
+}
+
+// concatCSV writes the rows of name in each directory (or each file if name
+// is empty) to outFile under a single header. Missing inputs are skipped; if
+// none exist no file is written and -1 is returned.
+func concatCSV(outFile string, inputs []string, name string) (int, error) {
+	var header []string
+	var writer *csv.Writer
+	var out *os.File
+	rows := -1
+
+	for _, input := range inputs {
+		path := input
+		if name != "" {
+			path = filepath.Join(input, name)
+		}
+
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return rows, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+
+		csvReader := csv.NewReader(file)
+		csvReader.FieldsPerRecord = -1
+		fileHeader, err := csvReader.Read()
+		if err == io.EOF {
+			file.Close()
+			continue
+		}
+		if err != nil {
+			file.Close()
+			return rows, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if out == nil {
+			out, err = os.Create(outFile)
+			if err != nil {
+				file.Close()
+				return rows, fmt.Errorf("failed to create %s: %w", outFile, err)
+			}
+			defer out.Close()
+			writer = csv.NewWriter(out)
+			header = fileHeader
+			if err := writer.Write(header); err != nil {
+				file.Close()
+				return rows, fmt.Errorf("failed to write %s: %w", outFile, err)
+			}
+			rows = 0
+		} else if !slices.Equal(fileHeader, header) {
+			file.Close()
+			return rows, fmt.Errorf("%s has different columns than the other inputs", path)
+		}
+
+		for {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				file.Close()
+				return rows, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if err := writer.Write(record); err != nil {
+				file.Close()
+				return rows, fmt.Errorf("failed to write %s: %w", outFile, err)
+			}
+			rows++
+		}
+		file.Close()
+	}
+
+	if writer != nil {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return rows, fmt.Errorf("failed to write %s: %w", outFile, err)
+		}
+	}
+
+	return rows, nil
+}
+
+// missingShards returns the shards of the given count that weren't merged
+func missingShards(shards []string, count int) []string {
+	present := make(map[string]bool)
+	for _, shard := range shards {
+		present[shard] = true
+	}
+
+	var missing []string
+	for index := 1; index <= count; index++ {
+		shard := fmt.Sprintf("%d/%d", index, count)
+		if !present[shard] {
+			missing = append(missing, shard)
+		}
+	}
+	return missing
+}
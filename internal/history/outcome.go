@@ -0,0 +1,210 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"csvfire/internal/request"
+)
+
+// SentLogFile is the request log of a run directory
+const SentLogFile = "sent.csv"
+
+// RowOutcome is the combined result of every attempt of a row
+type RowOutcome struct {
+	Row           int
+	Success       bool   // True once any attempt succeeded
+	StatusCode    int    // Status code of the latest attempt
+	ErrorCategory string // Error category of the latest attempt
+	Attempts      int    // Number of logged executions of the row
+}
+
+// Attempted reports whether the row was processed by any execution, rather
+// than only read before a stopped run recorded it as not attempted
+func (o *RowOutcome) Attempted() bool {
+	return o.ErrorCategory != request.NotAttemptedCategory
+}
+
+// ReadOutcomes reads sent.csv of a run directory and returns the outcome of each row.
+// A row that succeeded in any attempt stays successful.
+func ReadOutcomes(runDir string) (map[int]*RowOutcome, error) {
+	file, err := os.Open(filepath.Join(runDir, SentLogFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sent log: %w", err)
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.FieldsPerRecord = -1
+
+	outcomes := make(map[int]*RowOutcome)
+	header := true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sent log: %w", err)
+		}
+		if header {
+			header = false
+			continue
+		}
+		if len(record) < 8 {
+			continue
+		}
+
+		row, err := strconv.Atoi(record[1])
+		if err != nil {
+			continue
+		}
+
+		// A row that was not attempted keeps the result of its last real attempt
+		outcome, ok := outcomes[row]
+		if ok && record[7] == request.NotAttemptedCategory {
+			continue
+		}
+		if !ok {
+			outcome = &RowOutcome{Row: row}
+			outcomes[row] = outcome
+		}
+		if record[7] != request.NotAttemptedCategory {
+			outcome.Attempts++
+		}
+		if outcome.Success {
+			continue
+		}
+
+		outcome.Success = record[4] == "true"
+		outcome.StatusCode, _ = strconv.Atoi(record[3])
+		outcome.ErrorCategory = record[7]
+		if outcome.ErrorCategory == "" && !outcome.Success {
+			outcome.ErrorCategory = "request_failed"
+		}
+	}
+
+	return outcomes, nil
+}
+
+// statusRange is an inclusive range of HTTP status codes
+type statusRange struct {
+	min, max int
+}
+
+// Filter selects failed rows by error category or status code
+type Filter struct {
+	categories map[string]bool
+	statuses   []statusRange
+	spec       string
+}
+
+// deterministicCategories fail the same way on every retry and are only
+// selected when asked for explicitly
+var deterministicCategories = map[string]bool{
+	"validation_error": true,
+	"template_error":   true,
+}
+
+// ParseFilter builds a filter from category names and a status list such as
+// "5xx,429,500-504". Without any criteria every retryable failure matches.
+func ParseFilter(categories []string, statuses string) (*Filter, error) {
+	filter := &Filter{categories: make(map[string]bool)}
+	var spec []string
+
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category != "" {
+			filter.categories[category] = true
+		}
+	}
+	if len(filter.categories) > 0 {
+		names := make([]string, 0, len(filter.categories))
+		for name := range filter.categories {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		spec = append(spec, "category="+strings.Join(names, ","))
+	}
+
+	if strings.TrimSpace(statuses) != "" {
+		for _, part := range strings.Split(statuses, ",") {
+			statusRange, err := parseStatusRange(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			filter.statuses = append(filter.statuses, statusRange)
+		}
+		spec = append(spec, "status="+strings.ReplaceAll(statuses, " ", ""))
+	}
+
+	filter.spec = strings.Join(spec, " ")
+	return filter, nil
+}
+
+// parseStatusRange parses "503", "5xx" or "500-504"
+func parseStatusRange(value string) (statusRange, error) {
+	lower := strings.ToLower(value)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") && lower[0] >= '1' && lower[0] <= '5' {
+		base := int(lower[0]-'0') * 100
+		return statusRange{min: base, max: base + 99}, nil
+	}
+
+	if from, to, ok := strings.Cut(value, "-"); ok {
+		low, err1 := strconv.Atoi(from)
+		high, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || low > high {
+			return statusRange{}, fmt.Errorf("invalid status range '%s'", value)
+		}
+		return statusRange{min: low, max: high}, nil
+	}
+
+	status, err := strconv.Atoi(value)
+	if err != nil {
+		return statusRange{}, fmt.Errorf("invalid status '%s'", value)
+	}
+	return statusRange{min: status, max: status}, nil
+}
+
+// Match reports whether a row outcome should be retried
+func (f *Filter) Match(outcome *RowOutcome) bool {
+	if outcome.Success {
+		return false
+	}
+
+	if len(f.categories) == 0 && len(f.statuses) == 0 {
+		return !deterministicCategories[outcome.ErrorCategory]
+	}
+
+	if f.categories[outcome.ErrorCategory] {
+		return true
+	}
+	for _, statusRange := range f.statuses {
+		if outcome.StatusCode >= statusRange.min && outcome.StatusCode <= statusRange.max {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the filter criteria as given, for the run history
+func (f *Filter) String() string {
+	return f.spec
+}
+
+// SelectRows returns the row numbers whose outcome matches the filter
+func SelectRows(outcomes map[int]*RowOutcome, filter *Filter) map[int]bool {
+	selected := make(map[int]bool)
+	for row, outcome := range outcomes {
+		if filter.Match(outcome) {
+			selected[row] = true
+		}
+	}
+	return selected
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"csvfire/internal/config"
@@ -15,52 +16,74 @@ import (
 
 // LogEntry represents a single log entry
 type LogEntry struct {
-	Timestamp       time.Time                     `json:"timestamp"`
-	Row             int                           `json:"row"`
-	RequestID       string                        `json:"request_id"`
-	StatusCode      int                           `json:"status_code"`
-	Success         bool                          `json:"success"`
-	LatencyMs       int64                         `json:"latency_ms"`
-	Retries         int                           `json:"retries"`
-	ErrorCategory   string                        `json:"error_category"`
-	ErrorDetail     string                        `json:"error_detail"`
-	ResponsePreview string                        `json:"response_preview"`
-	RequestHash     string                        `json:"request_hash"`
+	Timestamp       time.Time `json:"timestamp"`
+	Row             int       `json:"row"`
+	RequestID       string    `json:"request_id"`
+	StatusCode      int       `json:"status_code"`
+	Success         bool      `json:"success"`
+	LatencyMs       int64     `json:"latency_ms"`
+	Retries         int       `json:"retries"`
+	ErrorCategory   string    `json:"error_category"`
+	ErrorDetail     string    `json:"error_detail"`
+	ResponsePreview string    `json:"response_preview"`
+	RequestHash     string    `json:"request_hash"`
 }
 
 // ValidationLogEntry represents a validation error log entry
 type ValidationLogEntry struct {
-	Timestamp time.Time                `json:"timestamp"`
-	Row       int                      `json:"row"`
+	Timestamp time.Time                   `json:"timestamp"`
+	Row       int                         `json:"row"`
 	Errors    []validator.ValidationError `json:"errors"`
 }
 
 // Logger handles CSV logging with channels for concurrent writing
 type Logger struct {
-	schema          *config.Schema
-	logDir          string
-	sentLogFile     *os.File
-	sentLogWriter   *csv.Writer
-	errorLogFile    *os.File
-	errorLogWriter  *csv.Writer
-	validateLogFile *os.File
+	schema            *config.Schema
+	logDir            string
+	sentLogFile       *os.File
+	sentLogWriter     *csv.Writer
+	errorLogFile      *os.File
+	errorLogWriter    *csv.Writer
+	validateLogFile   *os.File
 	validateLogWriter *csv.Writer
-	logChan         chan LogEntry
-	validateLogChan chan ValidationLogEntry
-	failedRows      []FailedRow
-	stopChan        chan struct{}
-	doneChan        chan struct{}
+	logChan           chan LogEntry
+	validateLogChan   chan ValidationLogEntry
+	failedChan        chan FailedRow
+	failedCount       atomic.Int64
+	failedFile        *os.File
+	failedWriter      *csv.Writer
+	failedFilename    string
+	exportNormalized  bool
+	stopChan          chan struct{}
+	doneChan          chan struct{}
 }
 
+// failedRowFlushInterval is how often the failed row export is flushed to disk
+const failedRowFlushInterval = time.Second
+
 // FailedRow represents a failed row for export
 type FailedRow struct {
-	RowNumber int
-	Data      map[string]string
-	Reason    string
+	RowNumber  int
+	Raw        map[string]string // Original values as read from the CSV
+	Normalized map[string]string // Normalized and transformed values (may be partial)
+	Reason     string
+	Detail     string
+	Attempts   int
 }
 
-// NewLogger creates a new logger instance
+// NewLogger creates a new logger instance, replacing existing logs in logDir
 func NewLogger(schema *config.Schema, logDir string) (*Logger, error) {
+	return newLogger(schema, logDir, false)
+}
+
+// NewAppendLogger creates a logger that appends to existing logs in logDir,
+// keeping the history of earlier attempts of the same run
+func NewAppendLogger(schema *config.Schema, logDir string) (*Logger, error) {
+	return newLogger(schema, logDir, true)
+}
+
+// newLogger creates a logger and starts its background goroutine
+func newLogger(schema *config.Schema, logDir string, appendLogs bool) (*Logger, error) {
 	// Ensure log directory exists
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
@@ -71,13 +94,13 @@ func NewLogger(schema *config.Schema, logDir string) (*Logger, error) {
 		logDir:          logDir,
 		logChan:         make(chan LogEntry, 1000),
 		validateLogChan: make(chan ValidationLogEntry, 1000),
-		failedRows:      make([]FailedRow, 0),
+		failedChan:      make(chan FailedRow, 1000),
 		stopChan:        make(chan struct{}),
 		doneChan:        make(chan struct{}),
 	}
 
 	// Initialize log files
-	if err := logger.initLogFiles(); err != nil {
+	if err := logger.initLogFiles(appendLogs); err != nil {
 		return nil, err
 	}
 
@@ -88,59 +111,116 @@ func NewLogger(schema *config.Schema, logDir string) (*Logger, error) {
 }
 
 // initLogFiles initializes CSV log files
-func (l *Logger) initLogFiles() error {
-	// Initialize sent.csv
-	sentLogPath := filepath.Join(l.logDir, "sent.csv")
+func (l *Logger) initLogFiles(appendLogs bool) error {
 	var err error
-	l.sentLogFile, err = os.Create(sentLogPath)
-	if err != nil {
-		return fmt.Errorf("failed to create sent log file: %w", err)
-	}
-	l.sentLogWriter = csv.NewWriter(l.sentLogFile)
 
-	// Write header for sent.csv
+	// Initialize sent.csv
 	sentHeaders := []string{
 		"ts", "row", "request_id", "status_code", "success", "latency_ms",
 		"retries", "error_category", "error_detail", "response_preview", "request_hash",
 	}
-	if err := l.sentLogWriter.Write(sentHeaders); err != nil {
-		return fmt.Errorf("failed to write sent log header: %w", err)
-	}
-	l.sentLogWriter.Flush()
-
-	// Initialize request_errors.csv
-	errorLogPath := filepath.Join(l.logDir, "request_errors.csv")
-	l.errorLogFile, err = os.Create(errorLogPath)
+	l.sentLogFile, l.sentLogWriter, err = openLogFile(filepath.Join(l.logDir, "sent.csv"), sentHeaders, appendLogs)
 	if err != nil {
-		return fmt.Errorf("failed to create error log file: %w", err)
+		return fmt.Errorf("failed to create sent log file: %w", err)
 	}
-	l.errorLogWriter = csv.NewWriter(l.errorLogFile)
 
-	// Write header for request_errors.csv
+	// Initialize request_errors.csv
 	errorHeaders := []string{
 		"ts", "row", "request_id", "error_category", "error_detail", "status_code",
 	}
-	if err := l.errorLogWriter.Write(errorHeaders); err != nil {
-		return fmt.Errorf("failed to write error log header: %w", err)
+	l.errorLogFile, l.errorLogWriter, err = openLogFile(filepath.Join(l.logDir, "request_errors.csv"), errorHeaders, appendLogs)
+	if err != nil {
+		return fmt.Errorf("failed to create error log file: %w", err)
 	}
-	l.errorLogWriter.Flush()
 
 	// Initialize validate_errors.csv
-	validateLogPath := filepath.Join(l.logDir, "validate_errors.csv")
-	l.validateLogFile, err = os.Create(validateLogPath)
+	validateHeaders := []string{
+		"ts", "row", "column", "value", "message",
+	}
+	l.validateLogFile, l.validateLogWriter, err = openLogFile(filepath.Join(l.logDir, "validate_errors.csv"), validateHeaders, appendLogs)
 	if err != nil {
 		return fmt.Errorf("failed to create validation log file: %w", err)
 	}
-	l.validateLogWriter = csv.NewWriter(l.validateLogFile)
 
-	// Write header for validate_errors.csv
-	validateHeaders := []string{
-		"ts", "row", "column", "value", "message",
+	return nil
+}
+
+// openLogFile creates or appends to a CSV log file. The header is written
+// only when the file is new or empty.
+func openLogFile(path string, headers []string, appendLogs bool) (*os.File, *csv.Writer, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendLogs {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
 	}
-	if err := l.validateLogWriter.Write(validateHeaders); err != nil {
-		return fmt.Errorf("failed to write validation log header: %w", err)
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, nil, err
 	}
-	l.validateLogWriter.Flush()
+
+	writer := csv.NewWriter(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		if err := writer.Write(headers); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to write header: %w", err)
+		}
+		writer.Flush()
+	}
+
+	return file, writer, nil
+}
+
+// StartFailedRowExport streams failed rows to a CSV file as they occur.
+// Rows keep their original raw values in schema column order so the file can
+// be fixed and fed back into run; failure metadata follows as extra columns.
+// It must be called before the first LogRequest.
+func (l *Logger) StartFailedRowExport(filename string, includeNormalized bool) error {
+	if dir := filepath.Dir(filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create failed rows directory: %w", err)
+		}
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create failed rows file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+
+	// Write header (original column names, optional normalized values, failure metadata)
+	columns := l.schema.GetColumnNames()
+	headers := append([]string{}, columns...)
+	if includeNormalized {
+		for _, colName := range columns {
+			headers = append(headers, config.NormalizedColumnPrefix+colName)
+		}
+	}
+	for _, colName := range l.schema.GetComputedNames() {
+		headers = append(headers, config.ComputedColumnPrefix+colName)
+	}
+	headers = append(headers,
+		config.FailureReasonColumn,
+		config.FailureDetailColumn,
+		config.SourceRowColumn,
+		config.AttemptsColumn,
+	)
+	if err := writer.Write(headers); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	writer.Flush()
+
+	l.failedFile = file
+	l.failedWriter = writer
+	l.failedFilename = filename
+	l.exportNormalized = includeNormalized
 
 	return nil
 }
@@ -154,9 +234,6 @@ func (l *Logger) LogRequest(rowNum int, validationResult *validator.ValidationRe
 			Row:       rowNum,
 			Errors:    validationResult.Errors,
 		}
-
-		// Add to failed rows
-		l.addFailedRow(rowNum, validationResult.Data, "validation_failed")
 	}
 
 	// Log request result
@@ -175,31 +252,70 @@ func (l *Logger) LogRequest(rowNum int, validationResult *validator.ValidationRe
 		}
 
 		l.logChan <- entry
+	}
 
-		// Add to failed rows if request failed
-		if !requestResult.Success {
-			reason := requestResult.ErrorCategory
-			if reason == "" {
-				reason = "request_failed"
-			}
-			l.addFailedRow(rowNum, validationResult.Data, reason)
+	// Add to failed rows
+	if !validationResult.Valid {
+		l.addFailedRow(rowNum, validationResult, "validation_error", validationDetail(validationResult.Errors), 0)
+	} else if requestResult != nil && !requestResult.Success {
+		reason := requestResult.ErrorCategory
+		if reason == "" {
+			reason = "request_failed"
+		}
+
+		detail := requestResult.ErrorDetail
+		if detail == "" && requestResult.StatusCode != 0 {
+			detail = fmt.Sprintf("status %d: %s", requestResult.StatusCode, requestResult.ResponsePreview)
 		}
+
+		// Template errors and rows that were not attempted never reach the network
+		attempts := 0
+		if reason != "template_error" && reason != request.NotAttemptedCategory {
+			attempts = requestResult.Retries + 1
+		}
+
+		l.addFailedRow(rowNum, validationResult, reason, detail, attempts)
 	}
 }
 
-// addFailedRow adds a row to the failed rows list
-func (l *Logger) addFailedRow(rowNum int, data map[string]string, reason string) {
-	l.failedRows = append(l.failedRows, FailedRow{
-		RowNumber: rowNum,
-		Data:      data,
-		Reason:    reason,
-	})
+// addFailedRow counts a failed row and queues it for export
+func (l *Logger) addFailedRow(rowNum int, validationResult *validator.ValidationResult, reason, detail string, attempts int) {
+	l.failedCount.Add(1)
+
+	if l.failedWriter == nil {
+		return
+	}
+
+	l.failedChan <- FailedRow{
+		RowNumber:  rowNum,
+		Raw:        validationResult.Raw,
+		Normalized: validationResult.Data,
+		Reason:     reason,
+		Detail:     detail,
+		Attempts:   attempts,
+	}
+}
+
+// validationDetail summarizes validation errors as "column: message" pairs
+func validationDetail(errors []validator.ValidationError) string {
+	parts := make([]string, 0, len(errors))
+	for _, validationError := range errors {
+		if validationError.Column == "" {
+			parts = append(parts, validationError.Message)
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s", validationError.Column, validationError.Message))
+		}
+	}
+	return strings.Join(parts, "; ")
 }
 
 // runLogger runs the background logging goroutine
 func (l *Logger) runLogger() {
 	defer close(l.doneChan)
 
+	flushTicker := time.NewTicker(failedRowFlushInterval)
+	defer flushTicker.Stop()
+
 	for {
 		select {
 		case entry := <-l.logChan:
@@ -211,6 +327,14 @@ func (l *Logger) runLogger() {
 		case validateEntry := <-l.validateLogChan:
 			l.writeValidationLog(validateEntry)
 
+		case failedRow := <-l.failedChan:
+			l.writeFailedRow(failedRow)
+
+		case <-flushTicker.C:
+			if l.failedWriter != nil {
+				l.failedWriter.Flush()
+			}
+
 		case <-l.stopChan:
 			// Drain remaining logs
 			for {
@@ -222,6 +346,8 @@ func (l *Logger) runLogger() {
 					}
 				case validateEntry := <-l.validateLogChan:
 					l.writeValidationLog(validateEntry)
+				case failedRow := <-l.failedChan:
+					l.writeFailedRow(failedRow)
 				default:
 					return
 				}
@@ -287,6 +413,41 @@ func (l *Logger) writeValidationLog(entry ValidationLogEntry) {
 	l.validateLogWriter.Flush()
 }
 
+// writeFailedRow appends a row to the failed row export
+func (l *Logger) writeFailedRow(failedRow FailedRow) {
+	columns := l.schema.GetColumnNames()
+	record := make([]string, 0, len(columns)*2+4)
+
+	// Fill original column data
+	for _, colName := range columns {
+		record = append(record, failedRow.Raw[colName])
+	}
+
+	// Fill normalized column data
+	if l.exportNormalized {
+		for _, colName := range columns {
+			record = append(record, failedRow.Normalized[colName])
+		}
+	}
+
+	// Fill computed column values (empty if the row failed validation)
+	for _, colName := range l.schema.GetComputedNames() {
+		record = append(record, failedRow.Normalized[colName])
+	}
+
+	// Add failure metadata
+	record = append(record,
+		failedRow.Reason,
+		failedRow.Detail,
+		fmt.Sprintf("%d", failedRow.RowNumber),
+		fmt.Sprintf("%d", failedRow.Attempts),
+	)
+
+	if err := l.failedWriter.Write(record); err != nil {
+		fmt.Printf("Error writing failed row: %v\n", err)
+	}
+}
+
 // maskSensitiveData masks sensitive information in log data
 func (l *Logger) maskSensitiveData(value string) string {
 	// Check if any column in the schema is marked as secret
@@ -327,53 +488,21 @@ func (l *Logger) Close() {
 	if l.validateLogFile != nil {
 		l.validateLogFile.Close()
 	}
-}
 
-// ExportFailedRows exports failed rows to a CSV file
-func (l *Logger) ExportFailedRows(filename string) error {
-	if len(l.failedRows) == 0 {
-		return nil // No failed rows to export
+	if l.failedWriter != nil {
+		l.failedWriter.Flush()
 	}
+	if l.failedFile != nil {
+		l.failedFile.Close()
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create failed rows file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header (original column names plus reason)
-	headers := l.schema.GetColumnNames()
-	headers = append(headers, "failure_reason")
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
-	}
-
-	// Write failed rows
-	for _, failedRow := range l.failedRows {
-		record := make([]string, len(headers))
-		
-		// Fill original column data
-		for i, colName := range l.schema.GetColumnNames() {
-			if value, exists := failedRow.Data[colName]; exists {
-				record[i] = value
-			}
-		}
-		
-		// Add failure reason
-		record[len(record)-1] = failedRow.Reason
-
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write failed row: %w", err)
+		// Don't leave a header-only export behind
+		if l.failedCount.Load() == 0 {
+			os.Remove(l.failedFilename)
 		}
 	}
-
-	return nil
 }
 
 // GetFailedRowCount returns the number of failed rows
 func (l *Logger) GetFailedRowCount() int {
-	return len(l.failedRows)
+	return int(l.failedCount.Load())
 } 
\ No newline at end of file
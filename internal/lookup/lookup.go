@@ -0,0 +1,237 @@
+package lookup
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Source types of reference tables
+const (
+	SourceCSV    = "csv"
+	SourceJSON   = "json"
+	SourceSQLite = "sqlite"
+)
+
+// SQLiteDriver is the database/sql driver name used for SQLite sources. No
+// driver is linked by default; programs that need SQLite lookups import one
+// registering this name (e.g. modernc.org/sqlite).
+var SQLiteDriver = "sqlite"
+
+// Table is a reference table loaded in memory, with an index per looked up
+// column. Tables are read-only once loaded and safe for concurrent use.
+type Table struct {
+	columns []string
+	rows    []map[string]string
+	indexes map[string]map[string]int // column -> value -> first row holding it
+}
+
+// SourceType returns the source type of a file, from its extension if not given
+func SourceType(path, sourceType string) string {
+	if sourceType != "" {
+		return sourceType
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return SourceJSON
+	case ".db", ".sqlite", ".sqlite3":
+		return SourceSQLite
+	}
+	return SourceCSV
+}
+
+// Load reads a reference table; query selects the rows of SQLite sources
+func Load(path, sourceType, query string) (*Table, error) {
+	switch SourceType(path, sourceType) {
+	case SourceCSV:
+		return loadCSV(path)
+	case SourceJSON:
+		return loadJSON(path)
+	case SourceSQLite:
+		return loadSQLite(path, query)
+	}
+	return nil, fmt.Errorf("unsupported lookup type '%s' (csv, json, sqlite)", sourceType)
+}
+
+// loadCSV reads a CSV file with a header row
+func loadCSV(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lookup file: %w", err)
+	}
+
+	csvReader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	csvReader.TrimLeadingSpace = true
+
+	columns, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lookup CSV header: %w", err)
+	}
+
+	table := newTable(columns)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lookup CSV: %w", err)
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[column] = record[i]
+		}
+		table.rows = append(table.rows, row)
+	}
+
+	return table, nil
+}
+
+// loadJSON reads a JSON array of objects; values other than strings are kept
+// in their JSON form
+func loadJSON(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lookup file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("failed to parse lookup JSON (expected an array of objects): %w", err)
+	}
+
+	table := newTable(nil)
+	for _, object := range objects {
+		row := make(map[string]string, len(object))
+		for column, value := range object {
+			if !slices.Contains(table.columns, column) {
+				table.columns = append(table.columns, column)
+			}
+			switch v := value.(type) {
+			case nil:
+				row[column] = ""
+			case string:
+				row[column] = v
+			default:
+				encoded, _ := json.Marshal(v)
+				row[column] = string(encoded)
+			}
+		}
+		table.rows = append(table.rows, row)
+	}
+
+	return table, nil
+}
+
+// loadSQLite runs the query of a SQLite source and reads its result
+func loadSQLite(path, query string) (*Table, error) {
+	if !slices.Contains(sql.Drivers(), SQLiteDriver) {
+		return nil, fmt.Errorf("SQLite lookups need a '%s' database/sql driver, which this build does not include", SQLiteDriver)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open lookup database: %w", err)
+	}
+
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lookup database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lookup database: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lookup database: %w", err)
+	}
+
+	table := newTable(columns)
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("failed to read lookup database: %w", err)
+		}
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[column] = values[i].String
+		}
+		table.rows = append(table.rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lookup database: %w", err)
+	}
+
+	return table, nil
+}
+
+// newTable creates an empty table with the given columns
+func newTable(columns []string) *Table {
+	return &Table{
+		columns: columns,
+		indexes: make(map[string]map[string]int),
+	}
+}
+
+// Len returns the number of rows of the table
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// BuildIndex indexes a column for Has and Find. It must be called for every
+// looked up column before the table is shared.
+func (t *Table) BuildIndex(column string) error {
+	if !slices.Contains(t.columns, column) {
+		return fmt.Errorf("column '%s' not found (available: %s)", column, strings.Join(t.columns, ", "))
+	}
+	if _, ok := t.indexes[column]; ok {
+		return nil
+	}
+
+	index := make(map[string]int, len(t.rows))
+	for i, row := range t.rows {
+		if _, seen := index[row[column]]; !seen {
+			index[row[column]] = i
+		}
+	}
+	t.indexes[column] = index
+	return nil
+}
+
+// HasColumn reports whether the table has a column
+func (t *Table) HasColumn(column string) bool {
+	return slices.Contains(t.columns, column)
+}
+
+// Has reports whether a value appears in an indexed column
+func (t *Table) Has(column, value string) bool {
+	_, ok := t.indexes[column][value]
+	return ok
+}
+
+// Find returns the first row whose indexed column holds a value
+func (t *Table) Find(column, value string) (map[string]string, bool) {
+	i, ok := t.indexes[column][value]
+	if !ok {
+		return nil, false
+	}
+	return t.rows[i], true
+}
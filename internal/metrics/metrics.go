@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors exposed by the runner and client.
+// All methods are safe to call on a nil receiver so instrumentation stays optional.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestsTotal   *prometheus.CounterVec
+	inFlight        prometheus.Gauge
+	requestDuration prometheus.Histogram
+	attemptDuration prometheus.Histogram
+	retriesTotal    prometheus.Counter
+	limiterWait     prometheus.Histogram
+	rowsRead        prometheus.Counter
+	rowsValidated   *prometheus.CounterVec
+	rowsSkipped     prometheus.Counter
+}
+
+// NewMetrics creates a new metrics instance with its own registry
+func NewMetrics() *Metrics {
+	latencyBuckets := []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "csvfire_requests_total",
+			Help: "Total number of HTTP requests by final status code and error category.",
+		}, []string{"status", "category"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "csvfire_requests_in_flight",
+			Help: "Number of HTTP requests currently in flight.",
+		}),
+		requestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "csvfire_request_duration_seconds",
+			Help:    "Total request latency including retries and backoff.",
+			Buckets: latencyBuckets,
+		}),
+		attemptDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "csvfire_http_attempt_duration_seconds",
+			Help:    "Latency of individual HTTP attempts.",
+			Buckets: latencyBuckets,
+		}),
+		retriesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "csvfire_retries_total",
+			Help: "Total number of retried HTTP attempts.",
+		}),
+		limiterWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "csvfire_limiter_wait_seconds",
+			Help:    "Time spent waiting on the rate limiter.",
+			Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}),
+		rowsRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "csvfire_rows_read_total",
+			Help: "Total number of CSV rows read.",
+		}),
+		rowsValidated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "csvfire_rows_validated_total",
+			Help: "Total number of validated rows by result.",
+		}, []string{"result"}),
+		rowsSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "csvfire_rows_skipped_total",
+			Help: "Total number of rows skipped because they were already processed.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.requestsTotal,
+		m.inFlight,
+		m.requestDuration,
+		m.attemptDuration,
+		m.retriesTotal,
+		m.limiterWait,
+		m.rowsRead,
+		m.rowsValidated,
+		m.rowsSkipped,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handler returns an HTTP handler serving the metrics in Prometheus format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP server exposing /metrics on the given address.
+// The server is shut down when the context is cancelled.
+func (m *Metrics) Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Metrics server error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// ObserveRequest records the final outcome of a request
+func (m *Metrics) ObserveRequest(statusCode int, category string, latency time.Duration, retries int) {
+	if m == nil {
+		return
+	}
+	m.requestsTotal.WithLabelValues(strconv.Itoa(statusCode), category).Inc()
+	m.requestDuration.Observe(latency.Seconds())
+	m.retriesTotal.Add(float64(retries))
+}
+
+// ObserveAttempt records the latency of a single HTTP attempt
+func (m *Metrics) ObserveAttempt(latency time.Duration) {
+	if m == nil {
+		return
+	}
+	m.attemptDuration.Observe(latency.Seconds())
+}
+
+// IncInFlight increments the in-flight request gauge
+func (m *Metrics) IncInFlight() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Inc()
+}
+
+// DecInFlight decrements the in-flight request gauge
+func (m *Metrics) DecInFlight() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Dec()
+}
+
+// ObserveLimiterWait records time spent waiting on the rate limiter
+func (m *Metrics) ObserveLimiterWait(wait time.Duration) {
+	if m == nil {
+		return
+	}
+	m.limiterWait.Observe(wait.Seconds())
+}
+
+// IncRowsRead increments the rows read counter
+func (m *Metrics) IncRowsRead() {
+	if m == nil {
+		return
+	}
+	m.rowsRead.Inc()
+}
+
+// IncRowsValidated increments the validated rows counter
+func (m *Metrics) IncRowsValidated(valid bool) {
+	if m == nil {
+		return
+	}
+	result := "invalid"
+	if valid {
+		result = "valid"
+	}
+	m.rowsValidated.WithLabelValues(result).Inc()
+}
+
+// IncRowsSkipped increments the skipped rows counter
+func (m *Metrics) IncRowsSkipped() {
+	if m == nil {
+		return
+	}
+	m.rowsSkipped.Inc()
+}
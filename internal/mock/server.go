@@ -0,0 +1,301 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"csvfire/internal/config"
+)
+
+// Server is an embedded HTTP server that answers requests from a mock script
+type Server struct {
+	config   *config.MockConfig
+	listener net.Listener
+	server   *http.Server
+	rng      *rand.Rand
+	rngMu    sync.Mutex
+
+	statsMu sync.Mutex
+	stats   map[string]int
+}
+
+// NewServer creates a mock server for the given script
+func NewServer(mockConfig *config.MockConfig) *Server {
+	seed := mockConfig.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Server{
+		config: mockConfig,
+		rng:    rand.New(rand.NewSource(seed)),
+		stats:  make(map[string]int),
+	}
+}
+
+// Start starts listening on a random local port
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start mock server: %w", err)
+	}
+	s.listener = listener
+
+	s.server = &http.Server{
+		Handler:           http.HandlerFunc(s.handle),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Mock server error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close shuts the server down
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+// Stats returns the number of responses served by outcome (status code or error)
+func (s *Server) Stats() map[string]int {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	result := make(map[string]int, len(s.stats))
+	for k, v := range s.stats {
+		result[k] = v
+	}
+	return result
+}
+
+// Transport returns a RoundTripper that sends every request to the mock server,
+// keeping the original Host header so rules can still match on it
+func (s *Server) Transport() http.RoundTripper {
+	return &redirectTransport{addr: s.Addr(), base: &http.Transport{}}
+}
+
+// redirectTransport rewrites request URLs to point at the mock server
+type redirectTransport struct {
+	addr string
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redirected := req.Clone(req.Context())
+	redirected.URL.Scheme = "http"
+	redirected.URL.Host = t.addr
+	if redirected.Host == "" {
+		redirected.Host = req.URL.Host
+	}
+	return t.base.RoundTrip(redirected)
+}
+
+// handle serves a single scripted response
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rule := s.matchRule(r, body)
+
+	latency := s.config.Latency
+	if rule != nil && rule.Latency != nil {
+		latency = *rule.Latency
+	}
+	if !s.sleep(r.Context(), latency) {
+		return
+	}
+
+	// Injected errors
+	injected := ""
+	if rule != nil {
+		injected = rule.Error
+	} else {
+		roll := s.float()
+		switch {
+		case roll < s.config.Errors.TimeoutRate:
+			injected = "timeout"
+		case roll < s.config.Errors.TimeoutRate+s.config.Errors.ResetRate:
+			injected = "reset"
+		}
+	}
+
+	switch injected {
+	case "timeout":
+		s.record("timeout")
+		<-r.Context().Done()
+		return
+	case "reset":
+		s.record("reset")
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	var response config.MockResponse
+	if rule != nil {
+		response = config.MockResponse{Status: rule.Status, Body: rule.Body, Headers: rule.Headers}
+	} else {
+		response = s.pickResponse()
+	}
+	if response.Status == 0 {
+		response.Status = http.StatusOK
+	}
+
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	s.record(fmt.Sprintf("%d", response.Status))
+	w.WriteHeader(response.Status)
+	io.WriteString(w, response.Body)
+}
+
+// matchRule returns the first rule whose conditions all match the request
+func (s *Server) matchRule(r *http.Request, body []byte) *config.MockRule {
+	if len(s.config.Rules) == 0 {
+		return nil
+	}
+
+	var jsonBody map[string]interface{}
+	json.Unmarshal(body, &jsonBody)
+
+	for i := range s.config.Rules {
+		rule := &s.config.Rules[i]
+		matched := true
+		for key, expected := range rule.Match {
+			if requestField(r, jsonBody, key) != expected {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// requestField extracts a request field addressed by a match key
+func requestField(r *http.Request, jsonBody map[string]interface{}, key string) string {
+	switch {
+	case key == "method":
+		return r.Method
+	case key == "path":
+		return r.URL.Path
+	case key == "host":
+		return r.Host
+	case strings.HasPrefix(key, "query."):
+		return r.URL.Query().Get(strings.TrimPrefix(key, "query."))
+	case strings.HasPrefix(key, "header."):
+		return r.Header.Get(strings.TrimPrefix(key, "header."))
+	case strings.HasPrefix(key, "body."):
+		var current interface{} = jsonBody
+		for _, part := range strings.Split(strings.TrimPrefix(key, "body."), ".") {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			current = object[part]
+		}
+		if current == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", current)
+	default:
+		return ""
+	}
+}
+
+// pickResponse picks a response from the weighted status distribution
+func (s *Server) pickResponse() config.MockResponse {
+	if len(s.config.Responses) == 0 {
+		return config.MockResponse{Status: http.StatusOK}
+	}
+
+	total := 0.0
+	for _, response := range s.config.Responses {
+		total += responseWeight(response)
+	}
+
+	roll := s.float() * total
+	for _, response := range s.config.Responses {
+		roll -= responseWeight(response)
+		if roll < 0 {
+			return response
+		}
+	}
+
+	return s.config.Responses[len(s.config.Responses)-1]
+}
+
+// responseWeight returns the weight of a response, defaulting to 1
+func responseWeight(response config.MockResponse) float64 {
+	if response.Weight == 0 {
+		return 1
+	}
+	return response.Weight
+}
+
+// sleep waits for a random latency; it returns false if the request was cancelled
+func (s *Server) sleep(ctx context.Context, latency config.MockLatency) bool {
+	delay := latency.Min
+	if latency.Max > latency.Min {
+		delay += time.Duration(s.float() * float64(latency.Max-latency.Min))
+	}
+	if delay <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// float returns a random number in [0, 1)
+func (s *Server) float() float64 {
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	return s.rng.Float64()
+}
+
+// record counts a served outcome
+func (s *Server) record(outcome string) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats[outcome]++
+}
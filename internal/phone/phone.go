@@ -0,0 +1,242 @@
+// Package phone parses, validates and formats international phone numbers
+// using numbering plan metadata embedded in the binary.
+package phone
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Number types
+const (
+	Mobile        = "mobile"
+	Landline      = "landline"
+	FixedOrMobile = "fixed_or_mobile" // Numbering plans that don't tell them apart (NANP)
+	VoIP          = "voip"
+	TollFree      = "toll_free"
+	Business      = "business" // Korean 8-digit representative numbers
+)
+
+// Output formats
+const (
+	FormatE164          = "e164"          // +821012345678
+	FormatInternational = "international" // +82 10-1234-5678
+	FormatNational      = "national"      // 010-1234-5678
+)
+
+//go:embed regions.yaml
+var regionsYAML []byte
+
+// plan is the numbering plan of a region
+type plan struct {
+	Region              string       `yaml:"region"`
+	CountryCode         string       `yaml:"country_code"`
+	NationalPrefix      string       `yaml:"national_prefix"`
+	InternationalPrefix string       `yaml:"international_prefix"`
+	Types               []numberType `yaml:"types"`
+	Formats             []format     `yaml:"formats"`
+
+	internationalPrefix *regexp.Regexp
+}
+
+// numberType matches the national significant numbers of one type
+type numberType struct {
+	Type    string `yaml:"type"`
+	Pattern string `yaml:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// format groups the digits of matching national significant numbers
+type format struct {
+	Pattern       string `yaml:"pattern"`
+	National      string `yaml:"national"`
+	International string `yaml:"international"`
+
+	pattern *regexp.Regexp
+}
+
+var (
+	loadOnce sync.Once
+	plans    map[string]*plan // By region
+	loadErr  error
+)
+
+// loadPlans parses the embedded metadata once
+func loadPlans() (map[string]*plan, error) {
+	loadOnce.Do(func() {
+		var list []*plan
+		if err := yaml.Unmarshal(regionsYAML, &list); err != nil {
+			loadErr = fmt.Errorf("failed to parse phone metadata: %w", err)
+			return
+		}
+		plans = make(map[string]*plan, len(list))
+		for _, p := range list {
+			if p.InternationalPrefix != "" {
+				p.internationalPrefix = regexp.MustCompile("^(?:" + p.InternationalPrefix + ")")
+			}
+			for i := range p.Types {
+				p.Types[i].pattern = regexp.MustCompile("^(?:" + p.Types[i].Pattern + ")$")
+			}
+			for i := range p.Formats {
+				p.Formats[i].pattern = regexp.MustCompile("^(?:" + p.Formats[i].Pattern + ")$")
+			}
+			plans[p.Region] = p
+		}
+	})
+	return plans, loadErr
+}
+
+// Regions returns the supported region codes
+func Regions() []string {
+	loaded, _ := loadPlans()
+	regions := make([]string, 0, len(loaded))
+	for region := range loaded {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// IsSupportedRegion reports whether a region code has numbering plan metadata
+func IsSupportedRegion(region string) bool {
+	loaded, _ := loadPlans()
+	_, ok := loaded[strings.ToUpper(region)]
+	return ok
+}
+
+// IsValidFormat reports whether name is an output format
+func IsValidFormat(name string) bool {
+	switch name {
+	case FormatE164, FormatInternational, FormatNational:
+		return true
+	}
+	return false
+}
+
+// Number is a parsed, valid phone number
+type Number struct {
+	Region      string // Region code such as "KR"
+	CountryCode string // Country calling code such as "82"
+	National    string // National significant number (without country code and national prefix)
+	Type        string // Mobile, Landline, ...
+
+	plan *plan
+}
+
+// Separators allowed between digits
+var separators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "\t", "")
+
+var digitsOnly = regexp.MustCompile(`^[0-9]+$`)
+
+// Parse parses a phone number. Numbers starting with "+" or the international
+// prefix of defaultRegion are read with their country code; other numbers are
+// read as numbers of defaultRegion, with or without the national prefix.
+func Parse(value, defaultRegion string) (*Number, error) {
+	loaded, err := loadPlans()
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned := separators.Replace(strings.TrimSpace(value))
+	international := strings.HasPrefix(cleaned, "+")
+	cleaned = strings.TrimPrefix(cleaned, "+")
+	if !digitsOnly.MatchString(cleaned) {
+		return nil, fmt.Errorf("invalid phone number: %q", value)
+	}
+
+	if !international {
+		region, ok := loaded[strings.ToUpper(defaultRegion)]
+		if !ok {
+			if defaultRegion == "" {
+				return nil, fmt.Errorf("phone number without country code needs a default region: %q", value)
+			}
+			return nil, fmt.Errorf("unsupported phone region: %s", defaultRegion)
+		}
+
+		if prefix := region.internationalPrefix; prefix != nil && prefix.MatchString(cleaned) {
+			cleaned = prefix.ReplaceAllString(cleaned, "")
+			international = true
+		} else {
+			// With the national prefix first, then as a bare national number
+			if region.NationalPrefix != "" && strings.HasPrefix(cleaned, region.NationalPrefix) {
+				if number := region.match(strings.TrimPrefix(cleaned, region.NationalPrefix)); number != nil {
+					return number, nil
+				}
+			}
+			if number := region.match(cleaned); number != nil {
+				return number, nil
+			}
+		}
+	}
+
+	if international {
+		for _, region := range loaded {
+			if national, ok := strings.CutPrefix(cleaned, region.CountryCode); ok {
+				if number := region.match(national); number != nil {
+					return number, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("invalid phone number: %q", value)
+}
+
+// match returns the number if national is a valid national significant number
+func (p *plan) match(national string) *Number {
+	for _, t := range p.Types {
+		if t.pattern.MatchString(national) {
+			return &Number{
+				Region:      p.Region,
+				CountryCode: p.CountryCode,
+				National:    national,
+				Type:        t.Type,
+				plan:        p,
+			}
+		}
+	}
+	return nil
+}
+
+// E164 returns the number in E.164 form: +821012345678
+func (n *Number) E164() string {
+	return "+" + n.CountryCode + n.National
+}
+
+// Format returns the number in an output format (FormatE164 if unknown)
+func (n *Number) Format(name string) string {
+	switch name {
+	case FormatNational:
+		for _, f := range n.plan.Formats {
+			if f.pattern.MatchString(n.National) {
+				return f.pattern.ReplaceAllString(n.National, f.National)
+			}
+		}
+		return n.plan.NationalPrefix + n.National
+	case FormatInternational:
+		for _, f := range n.plan.Formats {
+			if f.pattern.MatchString(n.National) {
+				return "+" + n.CountryCode + " " + f.pattern.ReplaceAllString(n.National, f.International)
+			}
+		}
+		return "+" + n.CountryCode + " " + n.National
+	default:
+		return n.E164()
+	}
+}
+
+// IsType reports whether the number is of a type. Numbers whose plan can't
+// tell mobile and landline apart match both.
+func (n *Number) IsType(numberType string) bool {
+	if n.Type == numberType {
+		return true
+	}
+	return n.Type == FixedOrMobile && (numberType == Mobile || numberType == Landline)
+}
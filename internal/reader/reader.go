@@ -2,10 +2,12 @@ package reader
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
 	"csvfire/internal/config"
 	"csvfire/internal/runner"
@@ -13,8 +15,10 @@ import (
 
 // CSVReader handles streaming CSV reading
 type CSVReader struct {
-	schema   *config.Schema
-	filename string
+	schema    *config.Schema
+	filename  string
+	rowFilter func(rowNumber int) bool
+	selection *Selection
 }
 
 // NewCSVReader creates a new CSV reader
@@ -25,10 +29,36 @@ func NewCSVReader(schema *config.Schema, filename string) *CSVReader {
 	}
 }
 
-// ReadRows reads CSV rows and sends them to the tasks channel
-func (r *CSVReader) ReadRows(tasksChan chan<- runner.RowTask) error {
+// SetRowFilter restricts ReadRows to rows for which filter returns true
+func (r *CSVReader) SetRowFilter(filter func(rowNumber int) bool) {
+	r.rowFilter = filter
+}
+
+// SetSelection restricts reading to the rows picked by a selection
+func (r *CSVReader) SetSelection(selection *Selection) {
+	if selection != nil && selection.IsEmpty() {
+		selection = nil
+	}
+	r.selection = selection
+}
+
+// ReadRows reads CSV rows and sends them to the tasks channel. Reading stops
+// without an error when ctx is done; the rest of the file is left unread.
+func (r *CSVReader) ReadRows(ctx context.Context, tasksChan chan<- runner.RowTask) error {
 	defer close(tasksChan)
 
+	return r.ForEachRow(func(task runner.RowTask) bool {
+		select {
+		case tasksChan <- task:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// ForEachRow calls fn for every selected row in file order until fn returns false
+func (r *CSVReader) ForEachRow(fn func(task runner.RowTask) bool) error {
 	file, err := os.Open(r.filename)
 	if err != nil {
 		return fmt.Errorf("failed to open CSV file: %w", err)
@@ -39,8 +69,8 @@ func (r *CSVReader) ReadRows(tasksChan chan<- runner.RowTask) error {
 	bufferedReader := bufio.NewReader(file)
 	csvReader := csv.NewReader(bufferedReader)
 	
-	// Configure CSV reader
-	csvReader.FieldsPerRecord = len(r.schema.Columns)
+	// Configure CSV reader (field count must match the header)
+	csvReader.FieldsPerRecord = 0
 	csvReader.TrimLeadingSpace = true
 
 	// Read header row
@@ -66,38 +96,60 @@ func (r *CSVReader) ReadRows(tasksChan chan<- runner.RowTask) error {
 			return fmt.Errorf("failed to read CSV row %d: %w", rowNumber, err)
 		}
 
+		// Rows from a failed row export keep their original row number
+		taskRow := r.sourceRowNumber(headers, record, rowNumber)
+		if r.rowFilter != nil && !r.rowFilter(taskRow) {
+			rowNumber++
+			continue
+		}
+
 		// Convert record to map
-		data := make(map[string]string)
-		for i, value := range record {
-			if i < len(headers) {
-				data[headers[i]] = value
+		data := r.recordToMap(headers, record)
+
+		if r.selection != nil {
+			selected, err := r.selection.Match(taskRow, data)
+			if err != nil {
+				return err
+			}
+			if !selected {
+				rowNumber++
+				continue
 			}
 		}
 
 		// Generate request ID
-		requestID := fmt.Sprintf("req_%d_%d", rowNumber, r.generateRowHash(data))
+		requestID := fmt.Sprintf("req_%d_%d", taskRow, r.generateRowHash(record[:len(expectedHeaders)]))
 
 		// Send task
 		task := runner.RowTask{
-			RowNumber: rowNumber,
+			RowNumber: taskRow,
 			Data:      data,
 			RequestID: requestID,
 		}
 
-		tasksChan <- task
+		if !fn(task) {
+			return nil
+		}
 		rowNumber++
 	}
 
 	return nil
 }
 
-// validateHeaders validates that CSV headers match schema columns
+// validateHeaders validates that CSV headers match schema columns.
+// Failed row export metadata columns may follow the schema columns.
 func (r *CSVReader) validateHeaders(headers, expectedHeaders []string) error {
-	if len(headers) != len(expectedHeaders) {
+	if len(headers) < len(expectedHeaders) {
 		return fmt.Errorf("header count mismatch: got %d, expected %d", len(headers), len(expectedHeaders))
 	}
 
 	for i, header := range headers {
+		if i >= len(expectedHeaders) {
+			if !config.IsFailedRowMetaColumn(header) {
+				return fmt.Errorf("header count mismatch: got %d, expected %d", len(headers), len(expectedHeaders))
+			}
+			continue
+		}
 		if header != expectedHeaders[i] {
 			return fmt.Errorf("header mismatch at position %d: got '%s', expected '%s'", i, header, expectedHeaders[i])
 		}
@@ -106,11 +158,36 @@ func (r *CSVReader) validateHeaders(headers, expectedHeaders []string) error {
 	return nil
 }
 
-// generateRowHash generates a simple hash for the row data
-func (r *CSVReader) generateRowHash(data map[string]string) int {
+// recordToMap converts a record to a column map, dropping export metadata columns
+func (r *CSVReader) recordToMap(headers, record []string) map[string]string {
+	data := make(map[string]string)
+	for i, value := range record {
+		if i < len(headers) && !config.IsFailedRowMetaColumn(headers[i]) {
+			data[headers[i]] = value
+		}
+	}
+	return data
+}
+
+// sourceRowNumber returns the original row number recorded in a failed row
+// export, or the given fallback for regular CSV files
+func (r *CSVReader) sourceRowNumber(headers, record []string, fallback int) int {
+	for i, header := range headers {
+		if header == config.SourceRowColumn && i < len(record) {
+			if rowNumber, err := strconv.Atoi(record[i]); err == nil && rowNumber > 0 {
+				return rowNumber
+			}
+		}
+	}
+	return fallback
+}
+
+// generateRowHash generates a simple hash for the row data.
+// Fields are hashed in column order so request IDs are stable across runs.
+func (r *CSVReader) generateRowHash(record []string) int {
 	hash := 0
-	for key, value := range data {
-		for _, c := range key + value {
+	for _, value := range record {
+		for _, c := range value + "\x00" {
 			hash = 31*hash + int(c)
 		}
 	}
@@ -120,8 +197,18 @@ func (r *CSVReader) generateRowHash(data map[string]string) int {
 	return hash
 }
 
-// CountRows counts the number of data rows in the CSV file (excluding header)
+// CountRows counts the number of data rows in the CSV file (excluding header).
+// With a row filter or selection only the rows that would be read are counted.
 func (r *CSVReader) CountRows() (int, error) {
+	if r.rowFilter != nil || r.selection != nil {
+		count := 0
+		err := r.ForEachRow(func(task runner.RowTask) bool {
+			count++
+			return true
+		})
+		return count, err
+	}
+
 	file, err := os.Open(r.filename)
 	if err != nil {
 		return 0, fmt.Errorf("failed to open CSV file: %w", err)
@@ -165,7 +252,7 @@ func (r *CSVReader) GetPreviewRows(limit int) ([]map[string]string, error) {
 
 	bufferedReader := bufio.NewReader(file)
 	csvReader := csv.NewReader(bufferedReader)
-	csvReader.FieldsPerRecord = len(r.schema.Columns)
+	csvReader.FieldsPerRecord = 0
 	csvReader.TrimLeadingSpace = true
 
 	// Read header row
@@ -187,12 +274,7 @@ func (r *CSVReader) GetPreviewRows(limit int) ([]map[string]string, error) {
 		}
 
 		// Convert record to map
-		data := make(map[string]string)
-		for i, value := range record {
-			if i < len(headers) {
-				data[headers[i]] = value
-			}
-		}
+		data := r.recordToMap(headers, record)
 
 		rows = append(rows, data)
 		count++
@@ -203,63 +285,18 @@ func (r *CSVReader) GetPreviewRows(limit int) ([]map[string]string, error) {
 
 // ValidateRowsStream reads CSV rows one by one and validates them without loading all into memory
 func (r *CSVReader) ValidateRowsStream(validator func(rowNum int, data map[string]string) (bool, []error)) (totalRows, validRows, errorCount int, err error) {
-	file, err := os.Open(r.filename)
-	if err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to open CSV file: %w", err)
-	}
-	defer file.Close()
-
-	// Create CSV reader with buffering for better performance
-	bufferedReader := bufio.NewReader(file)
-	csvReader := csv.NewReader(bufferedReader)
-	
-	// Configure CSV reader
-	csvReader.FieldsPerRecord = len(r.schema.Columns)
-	csvReader.TrimLeadingSpace = true
-
-	// Read header row
-	headers, err := csvReader.Read()
-	if err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to read CSV header: %w", err)
-	}
-
-	// Validate headers match schema
-	expectedHeaders := r.schema.GetColumnNames()
-	if err := r.validateHeaders(headers, expectedHeaders); err != nil {
-		return 0, 0, 0, fmt.Errorf("header validation failed: %w", err)
-	}
-
-	// Read data rows one by one
-	rowNumber := 1 // Start from 1 (excluding header)
-	for {
-		record, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return totalRows, validRows, errorCount, fmt.Errorf("failed to read CSV row %d: %w", rowNumber, err)
-		}
-
-		// Convert record to map
-		data := make(map[string]string)
-		for i, value := range record {
-			if i < len(headers) {
-				data[headers[i]] = value
-			}
-		}
-
+	err = r.ForEachRow(func(task runner.RowTask) bool {
 		// Validate the row
-		isValid, errors := validator(rowNumber, data)
+		isValid, errors := validator(task.RowNumber, task.Data)
 		totalRows++
-		
+
 		if isValid {
 			validRows++
 		} else {
 			errorCount += len(errors)
 		}
+		return true
+	})
 
-		rowNumber++
-	}
-
-	return totalRows, validRows, errorCount, nil
-} 
\ No newline at end of file
+	return totalRows, validRows, errorCount, err
+}
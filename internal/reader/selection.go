@@ -0,0 +1,265 @@
+package reader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+
+	"csvfire/internal/config"
+)
+
+// Selection picks the rows of a CSV file to process by row range, a filter
+// expression, random sampling and sharding. Rows keep their original row numbers.
+type Selection struct {
+	ranges     []rowRange
+	rowsSpec   string
+	where      *vm.Program
+	whereSpec  string
+	sample     float64 // Fraction of rows to keep (0 = all)
+	seed       int64
+	normalizer func(rowNumber int, data map[string]string) map[string]string
+	usesNorm   bool // The filter expression reads normalized values
+	shardIndex int  // 1-based shard of this host (0 = no sharding)
+	shardCount int
+	shardKey   string // Column whose value assigns rows to shards (empty = row number)
+}
+
+// rowRange is an inclusive range of row numbers (end 0 = open-ended)
+type rowRange struct {
+	start int
+	end   int
+}
+
+// NewSelection creates a row selection from the --rows, --where and --sample
+// options. Empty options select every row.
+func NewSelection(schema *config.Schema, rows, where string, sample float64, seed int64) (*Selection, error) {
+	s := &Selection{
+		rowsSpec:  strings.TrimSpace(rows),
+		whereSpec: strings.TrimSpace(where),
+		sample:    sample,
+		seed:      seed,
+	}
+
+	if s.rowsSpec != "" {
+		ranges, err := parseRowRanges(s.rowsSpec)
+		if err != nil {
+			return nil, err
+		}
+		s.ranges = ranges
+	}
+
+	if sample < 0 || sample > 1 {
+		return nil, fmt.Errorf("sample must be between 0 and 1, got %g", sample)
+	}
+
+	if s.whereSpec != "" {
+		// Columns are available as variables holding the raw value, and through
+		// the raw and norm maps for names that aren't valid identifiers
+		env := map[string]any{
+			"raw":  map[string]string{},
+			"norm": map[string]string{},
+		}
+		for _, name := range schema.GetColumnNames() {
+			if _, reserved := env[name]; !reserved {
+				env[name] = ""
+			}
+		}
+
+		program, err := expr.Compile(s.whereSpec, expr.Env(env), expr.AsBool(), expr.Patch(&normDetector{usesNorm: &s.usesNorm}))
+		if err != nil {
+			return nil, fmt.Errorf("invalid where expression: %w", err)
+		}
+		s.where = program
+	}
+
+	return s, nil
+}
+
+// SetShard restricts the selection to one of count shards ("2/5" is index 2 of 5).
+// Rows are assigned by a hash of the key column, or of the row number if key is empty,
+// so every host running the same CSV picks a disjoint part of it.
+func (s *Selection) SetShard(schema *config.Schema, spec, key string) error {
+	indexText, countText, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	index, indexErr := strconv.Atoi(strings.TrimSpace(indexText))
+	count, countErr := strconv.Atoi(strings.TrimSpace(countText))
+	if !ok || indexErr != nil || countErr != nil || count <= 0 || index < 1 || index > count {
+		return fmt.Errorf("invalid shard '%s' (expected INDEX/COUNT such as 2/5)", spec)
+	}
+
+	if key != "" && schema.GetColumnByName(key) == nil {
+		return fmt.Errorf("shard key column '%s' not found in schema", key)
+	}
+
+	s.shardIndex = index
+	s.shardCount = count
+	s.shardKey = key
+	return nil
+}
+
+// Shard returns the shard of the selection as "index/count", or "" if not sharded
+func (s *Selection) Shard() string {
+	if s.shardCount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.shardIndex, s.shardCount)
+}
+
+// SetNormalizer sets the function providing normalized values for the norm
+// map of filter expressions
+func (s *Selection) SetNormalizer(normalizer func(rowNumber int, data map[string]string) map[string]string) {
+	s.normalizer = normalizer
+}
+
+// IsEmpty reports whether the selection keeps every row
+func (s *Selection) IsEmpty() bool {
+	return s.ranges == nil && s.where == nil && s.sample == 0 && s.shardCount == 0
+}
+
+// Match reports whether a row is selected
+func (s *Selection) Match(rowNumber int, data map[string]string) (bool, error) {
+	if s.ranges != nil && !s.inRanges(rowNumber) {
+		return false, nil
+	}
+
+	if s.sample > 0 && !s.sampled(rowNumber) {
+		return false, nil
+	}
+
+	if s.shardCount > 0 && s.shardOf(rowNumber, data) != s.shardIndex {
+		return false, nil
+	}
+
+	if s.where != nil {
+		env := make(map[string]any, len(data)+2)
+		for name, value := range data {
+			env[name] = value
+		}
+		env["raw"] = data
+
+		normalized := map[string]string{}
+		if s.usesNorm && s.normalizer != nil {
+			normalized = s.normalizer(rowNumber, data)
+		}
+		env["norm"] = normalized
+
+		output, err := expr.Run(s.where, env)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate where expression on row %d: %w", rowNumber, err)
+		}
+		if matched, _ := output.(bool); !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// String describes the selection for logs and the run history
+func (s *Selection) String() string {
+	var parts []string
+	if s.rowsSpec != "" {
+		parts = append(parts, "rows="+s.rowsSpec)
+	}
+	if s.whereSpec != "" {
+		parts = append(parts, "where="+s.whereSpec)
+	}
+	if s.sample > 0 {
+		parts = append(parts, fmt.Sprintf("sample=%g seed=%d", s.sample, s.seed))
+	}
+	if s.shardCount > 0 {
+		shard := "shard=" + s.Shard()
+		if s.shardKey != "" {
+			shard += " shard_key=" + s.shardKey
+		}
+		parts = append(parts, shard)
+	}
+	return strings.Join(parts, " ")
+}
+
+// inRanges reports whether a row number is inside one of the ranges
+func (s *Selection) inRanges(rowNumber int) bool {
+	for _, r := range s.ranges {
+		if rowNumber >= r.start && (r.end == 0 || rowNumber <= r.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled decides deterministically from the seed and row number whether a
+// row is part of the sample, so the same seed always selects the same rows
+func (s *Selection) sampled(rowNumber int) bool {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(s.seed))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(rowNumber))
+
+	h := fnv.New64a()
+	h.Write(buf[:])
+	return float64(h.Sum64())/float64(math.MaxUint64) < s.sample
+}
+
+// shardOf returns the 1-based shard a row belongs to
+func (s *Selection) shardOf(rowNumber int, data map[string]string) int {
+	h := fnv.New32a()
+	if s.shardKey != "" {
+		h.Write([]byte(strings.TrimSpace(data[s.shardKey])))
+	} else {
+		h.Write([]byte(strconv.Itoa(rowNumber)))
+	}
+	return int(h.Sum32()%uint32(s.shardCount)) + 1
+}
+
+// parseRowRanges parses row ranges such as "1000-1999", "5,10-20" or "500-"
+func parseRowRanges(spec string) ([]rowRange, error) {
+	var ranges []rowRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		startText, endText, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(startText))
+		if err != nil || start <= 0 {
+			return nil, fmt.Errorf("invalid row range '%s'", part)
+		}
+
+		r := rowRange{start: start, end: start}
+		if isRange {
+			r.end = 0
+			if endText = strings.TrimSpace(endText); endText != "" {
+				end, err := strconv.Atoi(endText)
+				if err != nil || end < start {
+					return nil, fmt.Errorf("invalid row range '%s'", part)
+				}
+				r.end = end
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("invalid row range '%s'", spec)
+	}
+	return ranges, nil
+}
+
+// normDetector records whether an expression reads the norm map, so rows are
+// only normalized when needed
+type normDetector struct {
+	usesNorm *bool
+}
+
+// Visit implements ast.Visitor
+func (d *normDetector) Visit(node *ast.Node) {
+	if ident, ok := (*node).(*ast.IdentifierNode); ok && ident.Value == "norm" {
+		*d.usesNorm = true
+	}
+}
@@ -0,0 +1,347 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+// WriteHTML writes a self-contained HTML report
+func (r *Report) WriteHTML(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML report: %w", err)
+	}
+	defer file.Close()
+
+	funcMap := template.FuncMap{
+		"barChart":  barChart,
+		"lineChart": lineChart,
+		"percent":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+		"datetime":  formatTime,
+	}
+
+	tmpl, err := template.New("report").Funcs(funcMap).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	if err := tmpl.Execute(file, r); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteMarkdown writes a Markdown report
+func (r *Report) WriteMarkdown(filename string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# csvfire 실행 리포트\n\n")
+	fmt.Fprintf(&b, "- 실행 디렉토리: `%s`\n", r.RunDir)
+	fmt.Fprintf(&b, "- 생성 시각: %s\n", formatTime(r.GeneratedAt))
+	fmt.Fprintf(&b, "- 실행 구간: %s ~ %s (%v)\n\n", formatTime(r.StartTime), formatTime(r.EndTime), r.Duration)
+
+	fmt.Fprintf(&b, "## 요약\n\n")
+	fmt.Fprintf(&b, "| 항목 | 값 |\n|---|---:|\n")
+	fmt.Fprintf(&b, "| 총 행 수 | %d |\n", r.TotalRows)
+	fmt.Fprintf(&b, "| 성공 | %d |\n", r.SuccessRows)
+	fmt.Fprintf(&b, "| 실패 | %d |\n", r.FailedRows)
+	if r.NotAttempted > 0 {
+		fmt.Fprintf(&b, "| 보내지 않은 행 | %d |\n", r.NotAttempted)
+	}
+	fmt.Fprintf(&b, "| 성공률 | %.1f%% |\n", r.SuccessRate)
+	fmt.Fprintf(&b, "| 전송된 요청 | %d |\n\n", r.SentRows)
+
+	fmt.Fprintf(&b, "## 오류 분류 (error_category)\n\n")
+	writeMarkdownCounts(&b, "분류", r.ByCategory)
+
+	fmt.Fprintf(&b, "## 상태 코드\n\n")
+	writeMarkdownCounts(&b, "상태 코드", r.ByStatus)
+
+	fmt.Fprintf(&b, "## 지연 시간\n\n")
+	fmt.Fprintf(&b, "| 평균 | p50 | p95 | p99 | 최대 |\n|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %dms | %dms | %dms | %dms | %dms |\n\n",
+		r.LatencyAvg, r.LatencyP50, r.LatencyP95, r.LatencyP99, r.LatencyMax)
+	writeMarkdownBars(&b, r.LatencyHistogram, "%.0f")
+
+	fmt.Fprintf(&b, "## 재시도\n\n")
+	fmt.Fprintf(&b, "- 총 재시도 횟수: %d\n", r.TotalRetries)
+	fmt.Fprintf(&b, "- 재시도가 발생한 행: %d\n\n", r.RetriedRows)
+	writeMarkdownCounts(&b, "재시도 횟수", r.RetryDistribution)
+
+	fmt.Fprintf(&b, "## 컬럼별 검증 오류 (상위 %d개)\n\n", len(r.ValidationErrors))
+	if len(r.ValidationErrors) == 0 {
+		fmt.Fprintf(&b, "검증 오류 없음\n\n")
+	} else {
+		fmt.Fprintf(&b, "| 컬럼 | 오류 수 | 대표 메시지 |\n|---|---:|---|\n")
+		for _, columnErrors := range r.ValidationErrors {
+			fmt.Fprintf(&b, "| %s | %d | %s |\n", escapeMarkdown(columnErrors.Column), columnErrors.Count, escapeMarkdown(columnErrors.TopMessage))
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	fmt.Fprintf(&b, "## 처리량 (%v 단위, req/s)\n\n", r.ThroughputInterval)
+	writeMarkdownBars(&b, r.Throughput, "%.2f")
+
+	fmt.Fprintf(&b, "## 실패한 행\n\n")
+	if r.FailedRowsTotal == 0 {
+		fmt.Fprintf(&b, "실패한 행 없음\n\n")
+	} else {
+		if r.FailedRowsTotal > len(r.FailedEntries) {
+			fmt.Fprintf(&b, "총 %d행 중 처음 %d행만 표시합니다.\n\n", r.FailedRowsTotal, len(r.FailedEntries))
+		}
+		fmt.Fprintf(&b, "| 행 | 요청 ID | 상태 코드 | 분류 | 상세 |\n|---:|---|---:|---|---|\n")
+		for _, entry := range r.FailedEntries {
+			fmt.Fprintf(&b, "| %d | %s | %d | %s | %s |\n", entry.Row, entry.RequestID, entry.StatusCode,
+				escapeMarkdown(entry.ErrorCategory), escapeMarkdown(truncate(entry.ErrorDetail, 120)))
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	if len(r.Links) > 0 {
+		fmt.Fprintf(&b, "## 로그 파일\n\n")
+		for _, link := range r.Links {
+			fmt.Fprintf(&b, "- [%s](%s)\n", link.Name, link.Path)
+		}
+	}
+
+	if err := os.WriteFile(filename, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+
+	return nil
+}
+
+// writeMarkdownCounts writes a count breakdown as a Markdown table
+func writeMarkdownCounts(b *strings.Builder, title string, counts []Count) {
+	if len(counts) == 0 {
+		fmt.Fprintf(b, "데이터 없음\n\n")
+		return
+	}
+
+	fmt.Fprintf(b, "| %s | 건수 | 비율 |\n|---|---:|---:|\n", title)
+	for _, count := range counts {
+		fmt.Fprintf(b, "| %s | %d | %.1f%% |\n", escapeMarkdown(count.Label), count.Count, count.Percent)
+	}
+	fmt.Fprintf(b, "\n")
+}
+
+// writeMarkdownBars writes buckets as a text bar chart inside a code block
+func writeMarkdownBars(b *strings.Builder, buckets []Bucket, valueFormat string) {
+	if len(buckets) == 0 {
+		fmt.Fprintf(b, "데이터 없음\n\n")
+		return
+	}
+
+	maxValue, labelWidth := 0.0, 0
+	for _, bucket := range buckets {
+		if bucket.Value > maxValue {
+			maxValue = bucket.Value
+		}
+		if width := len([]rune(bucket.Label)); width > labelWidth {
+			labelWidth = width
+		}
+	}
+
+	fmt.Fprintf(b, "```\n")
+	for _, bucket := range buckets {
+		width := 0
+		if maxValue > 0 {
+			width = int(bucket.Value / maxValue * 40)
+		}
+		padding := strings.Repeat(" ", labelWidth-len([]rune(bucket.Label)))
+		fmt.Fprintf(b, "%s%s | %s "+valueFormat+"\n", bucket.Label, padding, strings.Repeat("█", width), bucket.Value)
+	}
+	fmt.Fprintf(b, "```\n\n")
+}
+
+// barChart renders buckets as an inline SVG bar chart
+func barChart(buckets []Bucket) template.HTML {
+	const width, height, labelHeight = 640, 200, 20
+
+	if len(buckets) == 0 {
+		return template.HTML("<p>데이터 없음</p>")
+	}
+
+	maxValue := 0.0
+	for _, bucket := range buckets {
+		if bucket.Value > maxValue {
+			maxValue = bucket.Value
+		}
+	}
+
+	barWidth := float64(width) / float64(len(buckets))
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart">`, width, height+labelHeight)
+	for i, bucket := range buckets {
+		barHeight := 0.0
+		if maxValue > 0 {
+			barHeight = bucket.Value / maxValue * float64(height-20)
+		}
+		x := float64(i) * barWidth
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s: %g</title></rect>`,
+			x+2, float64(height)-barHeight, barWidth-4, barHeight, html.EscapeString(bucket.Label), bucket.Value)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="value">%g</text>`, x+barWidth/2, float64(height)-barHeight-4, bucket.Value)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s</text>`, x+barWidth/2, height+15, html.EscapeString(bucket.Label))
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// lineChart renders buckets as an inline SVG line chart
+func lineChart(buckets []Bucket) template.HTML {
+	const width, height, labelHeight = 640, 200, 20
+
+	if len(buckets) == 0 {
+		return template.HTML("<p>데이터 없음</p>")
+	}
+
+	maxValue := 0.0
+	for _, bucket := range buckets {
+		if bucket.Value > maxValue {
+			maxValue = bucket.Value
+		}
+	}
+
+	step := float64(width)
+	if len(buckets) > 1 {
+		step = float64(width) / float64(len(buckets)-1)
+	}
+
+	points := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		y := float64(height)
+		if maxValue > 0 {
+			y -= bucket.Value / maxValue * float64(height-20)
+		}
+		points[i] = fmt.Sprintf("%.1f,%.1f", float64(i)*step, y)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart">`, width, height+labelHeight)
+	fmt.Fprintf(&b, `<polyline points="%s" />`, strings.Join(points, " "))
+	fmt.Fprintf(&b, `<text x="0" y="12" class="axis">max %.2f req/s</text>`, maxValue)
+	fmt.Fprintf(&b, `<text x="0" y="%d" class="axis">%s</text>`, height+15, html.EscapeString(buckets[0].Label))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis end">%s</text>`, width, height+15, html.EscapeString(buckets[len(buckets)-1].Label))
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// formatTime formats timestamps for display
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// escapeMarkdown escapes characters that break Markdown tables
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// truncate shortens long strings for display
+func truncate(value string, maxLen int) string {
+	runes := []rune(value)
+	if len(runes) <= maxLen {
+		return value
+	}
+	return string(runes[:maxLen]) + "..."
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<title>csvfire 실행 리포트</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Malgun Gothic", sans-serif; margin: 2rem auto; max-width: 960px; color: #222; }
+h1 { border-bottom: 2px solid #e4572e; padding-bottom: .3rem; }
+h2 { margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; margin: .5rem 0; }
+th, td { border: 1px solid #ddd; padding: .3rem .6rem; text-align: left; font-size: .9rem; }
+th { background: #f5f5f5; }
+td.num { text-align: right; }
+.cards { display: flex; gap: 1rem; }
+.card { flex: 1; border: 1px solid #ddd; border-radius: 6px; padding: .8rem; }
+.card .value { font-size: 1.6rem; font-weight: bold; }
+.ok { color: #2e7d32; } .fail { color: #c62828; }
+.chart { width: 100%; height: auto; }
+.chart rect { fill: #e4572e; }
+.chart polyline { fill: none; stroke: #e4572e; stroke-width: 2; }
+.chart text { font-size: 10px; text-anchor: middle; fill: #555; }
+.chart text.axis { text-anchor: start; } .chart text.end { text-anchor: end; }
+tr:target { background: #fff3cd; }
+</style>
+</head>
+<body>
+<h1>csvfire 실행 리포트</h1>
+<p>실행 디렉토리: <code>{{.RunDir}}</code><br>
+생성 시각: {{datetime .GeneratedAt}}<br>
+실행 구간: {{datetime .StartTime}} ~ {{datetime .EndTime}} ({{.Duration}})</p>
+
+<h2>요약</h2>
+<div class="cards">
+<div class="card">총 행 수<div class="value">{{.TotalRows}}</div></div>
+<div class="card">성공<div class="value ok">{{.SuccessRows}}</div></div>
+<div class="card">실패<div class="value fail"><a href="#failed">{{.FailedRows}}</a></div></div>
+{{if .NotAttempted}}<div class="card">보내지 않은 행<div class="value">{{.NotAttempted}}</div></div>{{end}}
+<div class="card">성공률<div class="value">{{percent .SuccessRate}}</div></div>
+</div>
+
+<h2>오류 분류 (error_category)</h2>
+{{template "counts" .ByCategory}}
+
+<h2>상태 코드</h2>
+{{template "counts" .ByStatus}}
+
+<h2>지연 시간</h2>
+<table>
+<tr><th>평균</th><th>p50</th><th>p95</th><th>p99</th><th>최대</th></tr>
+<tr><td class="num">{{.LatencyAvg}}ms</td><td class="num">{{.LatencyP50}}ms</td><td class="num">{{.LatencyP95}}ms</td><td class="num">{{.LatencyP99}}ms</td><td class="num">{{.LatencyMax}}ms</td></tr>
+</table>
+{{barChart .LatencyHistogram}}
+
+<h2>재시도</h2>
+<p>총 재시도 횟수: {{.TotalRetries}} / 재시도가 발생한 행: {{.RetriedRows}}</p>
+{{template "counts" .RetryDistribution}}
+
+<h2>컬럼별 검증 오류</h2>
+{{if .ValidationErrors}}
+<table>
+<tr><th>컬럼</th><th>오류 수</th><th>대표 메시지</th></tr>
+{{range .ValidationErrors}}<tr><td>{{.Column}}</td><td class="num">{{.Count}}</td><td>{{.TopMessage}}</td></tr>
+{{end}}</table>
+{{else}}<p>검증 오류 없음</p>{{end}}
+
+<h2>처리량 ({{.ThroughputInterval}} 단위)</h2>
+{{lineChart .Throughput}}
+
+<h2 id="failed">실패한 행</h2>
+{{if .FailedEntries}}
+{{if gt .FailedRowsTotal (len .FailedEntries)}}<p>총 {{.FailedRowsTotal}}행 중 처음 {{len .FailedEntries}}행만 표시합니다.</p>{{end}}
+<table>
+<tr><th>행</th><th>요청 ID</th><th>상태 코드</th><th>분류</th><th>상세</th></tr>
+{{range .FailedEntries}}<tr id="row-{{.Row}}"><td class="num"><a href="#row-{{.Row}}">{{.Row}}</a></td><td>{{.RequestID}}</td><td class="num">{{.StatusCode}}</td><td>{{.ErrorCategory}}</td><td>{{.ErrorDetail}}</td></tr>
+{{end}}</table>
+{{else}}<p>실패한 행 없음</p>{{end}}
+
+{{if .Links}}
+<h2>로그 파일</h2>
+<ul>
+{{range .Links}}<li><a href="{{.Path}}">{{.Name}}</a></li>
+{{end}}</ul>
+{{end}}
+</body>
+</html>
+{{define "counts"}}{{if .}}
+<table>
+<tr><th>항목</th><th>건수</th><th>비율</th></tr>
+{{range .}}<tr><td>{{.Label}}</td><td class="num">{{.Count}}</td><td class="num">{{percent .Percent}}</td></tr>
+{{end}}</table>
+{{else}}<p>데이터 없음</p>{{end}}{{end}}
+`
@@ -0,0 +1,477 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"csvfire/internal/request"
+)
+
+// Log file names written by the logger into a run directory
+const (
+	SentLogFile     = "sent.csv"
+	ErrorLogFile    = "request_errors.csv"
+	ValidateLogFile = "validate_errors.csv"
+)
+
+// maxFailedRows caps the number of failed rows listed in a report
+const maxFailedRows = 500
+
+// latencyBounds are the upper bounds (ms) of the latency histogram buckets
+var latencyBounds = []int64{50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// throughputIntervals are the candidate bucket sizes for the throughput chart
+var throughputIntervals = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute, time.Hour,
+}
+
+// Entry represents a single row of sent.csv
+type Entry struct {
+	Timestamp     time.Time
+	Row           int
+	RequestID     string
+	StatusCode    int
+	Success       bool
+	LatencyMs     int64
+	Retries       int
+	ErrorCategory string
+	ErrorDetail   string
+}
+
+// Count is a labelled counter used for breakdowns
+type Count struct {
+	Label   string
+	Count   int
+	Percent float64
+}
+
+// Bucket is a labelled value used for charts
+type Bucket struct {
+	Label string
+	Value float64
+}
+
+// ColumnErrors summarizes validation errors for a single column
+type ColumnErrors struct {
+	Column     string
+	Count      int
+	TopMessage string
+}
+
+// Report holds the aggregated statistics of a run
+type Report struct {
+	RunDir      string
+	GeneratedAt time.Time
+	StartTime   time.Time
+	EndTime     time.Time
+	Duration    time.Duration
+
+	TotalRows    int
+	SuccessRows  int
+	FailedRows   int
+	NotAttempted int     // Rows a stopped run never sent; not failures
+	SuccessRate  float64 // Of the rows that were attempted
+	SentRows     int
+
+	ByCategory []Count
+	ByStatus   []Count
+
+	LatencyAvg       int64
+	LatencyP50       int64
+	LatencyP95       int64
+	LatencyP99       int64
+	LatencyMax       int64
+	LatencyHistogram []Bucket
+
+	TotalRetries      int
+	RetriedRows       int
+	RetryDistribution []Count
+
+	ValidationErrorTotal int
+	ValidationErrors     []ColumnErrors
+
+	ThroughputInterval time.Duration
+	Throughput         []Bucket
+
+	FailedRowsTotal int
+	FailedEntries   []Entry
+	Links           []Link
+}
+
+// Link points to a log file that sits next to the report
+type Link struct {
+	Name string
+	Path string
+}
+
+// Generate reads the log files of a run directory and aggregates them
+func Generate(runDir string) (*Report, error) {
+	entries, err := readSentLog(filepath.Join(runDir, SentLogFile))
+	if err != nil {
+		return nil, err
+	}
+
+	columnErrors, validationTotal, err := readValidationLog(filepath.Join(runDir, ValidateLogFile))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		RunDir:               runDir,
+		GeneratedAt:          time.Now(),
+		ValidationErrors:     columnErrors,
+		ValidationErrorTotal: validationTotal,
+	}
+
+	// Retries append to sent.csv, so totals use the final outcome of each row
+	// while latency and throughput cover every attempt
+	report.summarize(finalOutcomes(entries))
+	report.summarizeLatency(entries)
+	report.summarizeRetries(entries)
+	report.summarizeThroughput(entries)
+
+	for _, name := range []string{SentLogFile, ErrorLogFile, ValidateLogFile} {
+		if _, err := os.Stat(filepath.Join(runDir, name)); err == nil {
+			report.Links = append(report.Links, Link{Name: name, Path: name})
+		}
+	}
+
+	return report, nil
+}
+
+// AddLink adds a link to an additional file (e.g. failed row export)
+func (r *Report) AddLink(name, path string) {
+	r.Links = append(r.Links, Link{Name: name, Path: path})
+}
+
+// summarize computes totals and breakdowns by category and status code
+func (r *Report) summarize(entries []Entry) {
+	categories := make(map[string]int)
+	statuses := make(map[string]int)
+
+	for _, entry := range entries {
+		r.TotalRows++
+		if entry.ErrorCategory == request.NotAttemptedCategory {
+			r.NotAttempted++
+			continue
+		}
+		if entry.Success {
+			r.SuccessRows++
+		} else {
+			r.FailedRows++
+			r.FailedRowsTotal++
+			if len(r.FailedEntries) < maxFailedRows {
+				r.FailedEntries = append(r.FailedEntries, entry)
+			}
+
+			category := entry.ErrorCategory
+			if category == "" {
+				category = "request_failed"
+			}
+			categories[category]++
+		}
+
+		if isSent(entry) {
+			r.SentRows++
+			status := "-"
+			if entry.StatusCode > 0 {
+				status = strconv.Itoa(entry.StatusCode)
+			}
+			statuses[status]++
+		}
+
+		if r.StartTime.IsZero() || entry.Timestamp.Before(r.StartTime) {
+			r.StartTime = entry.Timestamp
+		}
+		if entry.Timestamp.After(r.EndTime) {
+			r.EndTime = entry.Timestamp
+		}
+	}
+
+	if attempted := r.TotalRows - r.NotAttempted; attempted > 0 {
+		r.SuccessRate = float64(r.SuccessRows) / float64(attempted) * 100
+	}
+	r.Duration = r.EndTime.Sub(r.StartTime)
+
+	sort.Slice(r.FailedEntries, func(i, j int) bool {
+		return r.FailedEntries[i].Row < r.FailedEntries[j].Row
+	})
+
+	r.ByCategory = sortedCounts(categories, r.FailedRows)
+	r.ByStatus = sortedCounts(statuses, r.SentRows)
+}
+
+// summarizeLatency computes percentiles and the histogram of sent requests
+func (r *Report) summarizeLatency(entries []Entry) {
+	var latencies []int64
+	for _, entry := range entries {
+		if isSent(entry) {
+			latencies = append(latencies, entry.LatencyMs)
+		}
+	}
+
+	buckets := make([]Bucket, len(latencyBounds)+1)
+	for i, bound := range latencyBounds {
+		buckets[i].Label = fmt.Sprintf("≤%s", formatMs(bound))
+	}
+	buckets[len(latencyBounds)].Label = fmt.Sprintf(">%s", formatMs(latencyBounds[len(latencyBounds)-1]))
+	r.LatencyHistogram = buckets
+
+	if len(latencies) == 0 {
+		return
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var sum int64
+	for _, latency := range latencies {
+		sum += latency
+		index := sort.Search(len(latencyBounds), func(i int) bool { return latency <= latencyBounds[i] })
+		buckets[index].Value++
+	}
+
+	r.LatencyAvg = sum / int64(len(latencies))
+	r.LatencyP50 = percentile(latencies, 50)
+	r.LatencyP95 = percentile(latencies, 95)
+	r.LatencyP99 = percentile(latencies, 99)
+	r.LatencyMax = latencies[len(latencies)-1]
+}
+
+// summarizeRetries computes retry totals and distribution
+func (r *Report) summarizeRetries(entries []Entry) {
+	distribution := make(map[string]int)
+	for _, entry := range entries {
+		if !isSent(entry) {
+			continue
+		}
+		r.TotalRetries += entry.Retries
+		if entry.Retries > 0 {
+			r.RetriedRows++
+		}
+		distribution[strconv.Itoa(entry.Retries)]++
+	}
+
+	r.RetryDistribution = sortedCounts(distribution, r.SentRows)
+	sort.Slice(r.RetryDistribution, func(i, j int) bool {
+		a, _ := strconv.Atoi(r.RetryDistribution[i].Label)
+		b, _ := strconv.Atoi(r.RetryDistribution[j].Label)
+		return a < b
+	})
+}
+
+// summarizeThroughput buckets completed requests over time
+func (r *Report) summarizeThroughput(entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	interval := throughputIntervals[len(throughputIntervals)-1]
+	for _, candidate := range throughputIntervals {
+		if r.Duration/candidate < 60 {
+			interval = candidate
+			break
+		}
+	}
+	r.ThroughputInterval = interval
+
+	start := r.StartTime.Truncate(interval)
+	bucketCount := int(r.EndTime.Sub(start)/interval) + 1
+	counts := make([]int, bucketCount)
+	for _, entry := range entries {
+		if entry.ErrorCategory == request.NotAttemptedCategory {
+			continue
+		}
+		index := int(entry.Timestamp.Sub(start) / interval)
+		if index >= 0 && index < bucketCount {
+			counts[index]++
+		}
+	}
+
+	r.Throughput = make([]Bucket, bucketCount)
+	for i, count := range counts {
+		r.Throughput[i] = Bucket{
+			Label: start.Add(time.Duration(i) * interval).Format("15:04:05"),
+			Value: float64(count) / interval.Seconds(),
+		}
+	}
+}
+
+// readSentLog parses sent.csv
+func readSentLog(filename string) ([]Entry, error) {
+	records, err := readCSV(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sent log: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(records))
+	for _, record := range records {
+		if len(record) < 9 {
+			continue
+		}
+
+		entry := Entry{
+			RequestID:     record[2],
+			Success:       record[4] == "true",
+			ErrorCategory: record[7],
+			ErrorDetail:   record[8],
+		}
+		entry.Timestamp, _ = time.Parse(time.RFC3339, record[0])
+		entry.Row, _ = strconv.Atoi(record[1])
+		entry.StatusCode, _ = strconv.Atoi(record[3])
+		entry.LatencyMs, _ = strconv.ParseInt(record[5], 10, 64)
+		entry.Retries, _ = strconv.Atoi(record[6])
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// readValidationLog parses validate_errors.csv and groups errors by column
+func readValidationLog(filename string) ([]ColumnErrors, int, error) {
+	records, err := readCSV(filename)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read validation log: %w", err)
+	}
+
+	counts := make(map[string]int)
+	messages := make(map[string]map[string]int)
+	total := 0
+	for _, record := range records {
+		if len(record) < 5 {
+			continue
+		}
+		column := record[2]
+		if column == "" {
+			column = "(row)"
+		}
+		counts[column]++
+		if messages[column] == nil {
+			messages[column] = make(map[string]int)
+		}
+		messages[column][record[4]]++
+		total++
+	}
+
+	result := make([]ColumnErrors, 0, len(counts))
+	for column, count := range counts {
+		topMessage, topCount := "", 0
+		for message, messageCount := range messages[column] {
+			if messageCount > topCount || (messageCount == topCount && message < topMessage) {
+				topMessage, topCount = message, messageCount
+			}
+		}
+		result = append(result, ColumnErrors{Column: column, Count: count, TopMessage: topMessage})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Column < result[j].Column
+	})
+	if len(result) > 10 {
+		result = result[:10]
+	}
+
+	return result, total, nil
+}
+
+// readCSV reads all records of a CSV file, skipping the header
+func readCSV(filename string) ([][]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	if _, err := reader.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return reader.ReadAll()
+}
+
+// finalOutcomes keeps one entry per row: the first successful attempt, or the
+// latest attempt if the row never succeeded. A row that was not attempted by a
+// later execution keeps the result of its last real attempt.
+func finalOutcomes(entries []Entry) []Entry {
+	index := make(map[int]int)
+	outcomes := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		i, ok := index[entry.Row]
+		if !ok {
+			index[entry.Row] = len(outcomes)
+			outcomes = append(outcomes, entry)
+			continue
+		}
+		if !outcomes[i].Success && entry.ErrorCategory != request.NotAttemptedCategory {
+			outcomes[i] = entry
+		}
+	}
+	return outcomes
+}
+
+// isSent reports whether the entry corresponds to an HTTP request that was sent
+func isSent(entry Entry) bool {
+	return entry.ErrorCategory != "validation_error" && entry.ErrorCategory != "template_error" &&
+		entry.ErrorCategory != request.NotAttemptedCategory
+}
+
+// sortedCounts converts a counter map into counts sorted by descending count
+func sortedCounts(counts map[string]int, total int) []Count {
+	result := make([]Count, 0, len(counts))
+	for label, count := range counts {
+		c := Count{Label: label, Count: count}
+		if total > 0 {
+			c.Percent = float64(count) / float64(total) * 100
+		}
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Label < result[j].Label
+	})
+
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// formatMs formats milliseconds for chart labels
+func formatMs(ms int64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%gs", float64(ms)/1000)
+	}
+	return fmt.Sprintf("%dms", ms)
+}
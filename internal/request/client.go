@@ -3,16 +3,25 @@ package request
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	"csvfire/internal/config"
+	"csvfire/internal/metrics"
+	"csvfire/internal/tracing"
 )
 
 // Client handles HTTP requests with retry logic and proxy support
@@ -21,6 +30,22 @@ type Client struct {
 	baseClient    *http.Client
 	maxRetries    int
 	timeout       time.Duration
+	metrics       *metrics.Metrics
+	transport     http.RoundTripper
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+// ErrReplayMismatch is returned by replay transports when no recorded
+// response matches a request; such requests are never retried
+var ErrReplayMismatch = errors.New("replay: no matching recorded response")
+
+// requestIDKey is the context key carrying the csvfire request ID
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID of the row being executed, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
 }
 
 // RequestResult holds the result of an HTTP request
@@ -53,6 +78,21 @@ func (c *Client) SetMaxRetries(maxRetries int) {
 	c.maxRetries = maxRetries
 }
 
+// SetMetrics sets the metrics collector used to instrument requests
+func (c *Client) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
+// SetTransport overrides the transport used for all requests (proxy settings are ignored)
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.transport = transport
+}
+
+// SetTransportWrapper wraps the transport of every request, including proxied ones
+func (c *Client) SetTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.wrapTransport = wrap
+}
+
 // Execute executes an HTTP request with retry logic
 func (c *Client) Execute(ctx context.Context, requestData *RequestData, requestID string) *RequestResult {
 	result := &RequestResult{
@@ -60,8 +100,32 @@ func (c *Client) Execute(ctx context.Context, requestData *RequestData, requestI
 		Headers:   make(map[string]string),
 	}
 
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	ctx, span := tracing.Start(ctx, "http.request",
+		attribute.String("csvfire.request_id", requestID),
+		attribute.String("http.request.method", requestData.Method),
+		attribute.String("url.full", requestData.URL))
+	defer span.End()
+
 	startTime := time.Now()
-	
+	defer func() {
+		category := result.ErrorCategory
+		if result.Success {
+			category = "none"
+		} else if category == "" {
+			category = "request_failed"
+		}
+		c.metrics.ObserveRequest(result.StatusCode, category, time.Since(startTime), result.Retries)
+
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", result.StatusCode),
+			attribute.Int("csvfire.retries", result.Retries),
+			attribute.Bool("csvfire.success", result.Success))
+		if !result.Success {
+			span.SetStatus(codes.Error, category)
+		}
+	}()
+
 	var lastErr error
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		result.Retries = attempt
@@ -70,8 +134,20 @@ func (c *Client) Execute(ctx context.Context, requestData *RequestData, requestI
 		client := c.createClientWithProxy(requestData.Proxy)
 		
 		// Execute the request
-		statusCode, responseBody, headers, err := c.executeRequest(ctx, client, requestData)
-		
+		attemptCtx, attemptSpan := tracing.Start(ctx, "http.attempt", attribute.Int("csvfire.attempt", attempt))
+		attemptStart := time.Now()
+		c.metrics.IncInFlight()
+		statusCode, responseBody, headers, err := c.executeRequest(attemptCtx, client, requestData)
+		c.metrics.DecInFlight()
+		c.metrics.ObserveAttempt(time.Since(attemptStart))
+
+		attemptSpan.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		if err != nil {
+			attemptSpan.RecordError(err)
+			attemptSpan.SetStatus(codes.Error, categorizeError(err))
+		}
+		attemptSpan.End()
+
 		result.StatusCode = statusCode
 		result.LatencyMs = time.Since(startTime).Milliseconds()
 		
@@ -96,6 +172,9 @@ func (c *Client) Execute(ctx context.Context, requestData *RequestData, requestI
 			// Don't sleep on the last attempt
 			if attempt < c.maxRetries {
 				backoffDelay := c.calculateBackoff(attempt)
+				span.AddEvent("retry.backoff", trace.WithAttributes(
+					attribute.Int("csvfire.attempt", attempt),
+					attribute.Int64("csvfire.backoff_ms", backoffDelay.Milliseconds())))
 				select {
 				case <-ctx.Done():
 					return result
@@ -139,6 +218,10 @@ func (c *Client) executeRequest(ctx context.Context, client *http.Client, reques
 		req.Header.Set(key, value)
 	}
 
+	// Propagate W3C trace context and record connection timings
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req = req.WithContext(httptrace.WithClientTrace(ctx, tracing.ClientTrace(trace.SpanFromContext(ctx))))
+
 	// Set default headers if not specified
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", "csvfire/1.0")
@@ -170,15 +253,22 @@ func (c *Client) createClientWithProxy(proxyURL string) *http.Client {
 		Timeout: c.timeout,
 	}
 
-	if proxyURL != "" {
+	var transport http.RoundTripper = http.DefaultTransport
+	if c.transport != nil {
+		transport = c.transport
+	} else if proxyURL != "" {
 		if parsedProxy, err := url.Parse(proxyURL); err == nil {
-			transport := &http.Transport{
+			transport = &http.Transport{
 				Proxy: http.ProxyURL(parsedProxy),
 			}
-			client.Transport = transport
 		}
 	}
 
+	if c.wrapTransport != nil {
+		transport = c.wrapTransport(transport)
+	}
+	client.Transport = transport
+
 	return client
 }
 
@@ -208,6 +298,11 @@ func (c *Client) calculateBackoff(attempt int) time.Duration {
 
 // shouldRetry determines if a request should be retried
 func shouldRetry(err error, statusCode int) bool {
+	// Never retry requests a replay cassette cannot answer
+	if errors.Is(err, ErrReplayMismatch) {
+		return false
+	}
+
 	// Retry on network errors (when statusCode is 0)
 	if statusCode == 0 {
 		return true
@@ -226,6 +321,14 @@ func shouldRetry(err error, statusCode int) bool {
 	return false
 }
 
+// NotAttemptedCategory is the error category of rows that were read but never
+// sent because the run stopped dispatching first
+const NotAttemptedCategory = "not_attempted"
+
+// CanceledCategory is the error category of requests whose context was
+// cancelled while in flight, e.g. by a forced shutdown
+const CanceledCategory = "canceled"
+
 // categorizeError categorizes errors for logging
 func categorizeError(err error) string {
 	if err == nil {
@@ -235,6 +338,8 @@ func categorizeError(err error) string {
 	errStr := err.Error()
 	
 	switch {
+	case errors.Is(err, ErrReplayMismatch):
+		return "replay_mismatch"
 	case strings.Contains(errStr, "timeout"):
 		return "timeout"
 	case strings.Contains(errStr, "connection refused"):
@@ -242,7 +347,7 @@ func categorizeError(err error) string {
 	case strings.Contains(errStr, "no such host"):
 		return "dns_error"
 	case strings.Contains(errStr, "context canceled"):
-		return "canceled"
+		return CanceledCategory
 	case strings.Contains(errStr, "context deadline exceeded"):
 		return "timeout"
 	default:
@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"fmt"
-	"regexp"
-	"strings"
 	"text/template"
-	"time"
 
 	"csvfire/internal/config"
+	"csvfire/internal/funcs"
+	"csvfire/internal/validator"
 )
 
 // TemplateRenderer handles request template rendering
@@ -28,16 +27,7 @@ func NewTemplateRenderer(requestConfig *config.RequestConfig) (*TemplateRenderer
 		headerTemplates: make(map[string]*template.Template),
 	}
 
-	// Create template functions
-	funcMap := template.FuncMap{
-		"dateFormat":  dateFormat,
-		"toE164KR":    toE164KR,
-		"mask":        mask,
-		"hash":        hash,
-		"upper":       strings.ToUpper,
-		"lower":       strings.ToLower,
-		"trim":        strings.TrimSpace,
-	}
+	funcMap := funcs.Map()
 
 	// Parse URL template
 	urlTmpl, err := template.New("url").Funcs(funcMap).Parse(requestConfig.URL)
@@ -86,8 +76,11 @@ type RequestData struct {
 	Hash    string            `json:"hash"`
 }
 
-// Render renders the request template with the given data
-func (tr *TemplateRenderer) Render(data map[string]string) (*RequestData, error) {
+// Render renders the request template for a validated row. Templates see the
+// typed values of the row, the normalized strings under .norm and the
+// original CSV strings under .raw.
+func (tr *TemplateRenderer) Render(row *validator.ValidationResult) (*RequestData, error) {
+	data := row.TemplateData()
 	result := &RequestData{
 		Method:  tr.requestConfig.Method,
 		Headers: make(map[string]string),
@@ -133,8 +126,9 @@ func (tr *TemplateRenderer) Render(data map[string]string) (*RequestData, error)
 		}
 	}
 
-	// Generate request hash for idempotency
-	result.Hash = tr.generateRequestHash(data)
+	// Generate request hash for idempotency. It uses the normalized strings so
+	// hashes (and checkpoints) don't depend on how values are typed.
+	result.Hash = tr.generateRequestHash(row.Data)
 
 	return result, nil
 }
@@ -174,62 +168,3 @@ func (tr *TemplateRenderer) generateRequestHash(data map[string]string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// Template functions
-
-// dateFormat formats a date string according to the given layout
-func dateFormat(layout, value string) string {
-	// Try parsing common formats
-	formats := []string{
-		"20060102",     // YYYYMMDD
-		"2006-01-02",   // YYYY-MM-DD
-		"01/02/2006",   // MM/DD/YYYY
-		"02/01/2006",   // DD/MM/YYYY
-	}
-	
-	var parsedTime time.Time
-	var err error
-	
-	for _, format := range formats {
-		parsedTime, err = time.Parse(format, value)
-		if err == nil {
-			break
-		}
-	}
-	
-	if err != nil {
-		return value // Return original if can't parse
-	}
-	
-	return parsedTime.Format(layout)
-}
-
-// toE164KR converts Korean phone numbers to E164 format
-func toE164KR(phone string) string {
-	// Remove all non-digit characters
-	cleaned := regexp.MustCompile(`\D`).ReplaceAllString(phone, "")
-	
-	// Handle Korean phone numbers
-	if len(cleaned) == 10 && strings.HasPrefix(cleaned, "0") {
-		// 010-XXXX-XXXX format -> +82-10-XXXX-XXXX
-		return "+82" + cleaned[1:]
-	} else if len(cleaned) == 11 && strings.HasPrefix(cleaned, "01") {
-		// 010-XXXX-XXXX format -> +82-10-XXXX-XXXX
-		return "+82" + cleaned[1:]
-	}
-	
-	return cleaned
-}
-
-// mask masks sensitive data
-func mask(value string) string {
-	if len(value) <= 4 {
-		return strings.Repeat("*", len(value))
-	}
-	return value[:2] + strings.Repeat("*", len(value)-4) + value[len(value)-2:]
-}
-
-// hash creates a SHA256 hash of the value
-func hash(value string) string {
-	h := sha256.Sum256([]byte(value))
-	return fmt.Sprintf("%x", h)
-} 
\ No newline at end of file
@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"csvfire/internal/config"
+	"csvfire/internal/request"
+)
+
+// Circuit breaker states reported in Progress
+const (
+	BreakerClosed   = "closed"    // Requests flow normally
+	BreakerOpen     = "open"      // Paused, waiting for the cooldown
+	BreakerHalfOpen = "half_open" // Paused, a single probe request is allowed
+	BreakerAborted  = "aborted"   // Dispatching stopped for good
+)
+
+// breaker is a circuit breaker guarding the requests of a single run.
+//
+// Closed: every result updates a sliding failure window and a consecutive 5xx
+// counter. Exceeding a threshold either aborts the run or opens the breaker.
+// Open: no request is sent until the cooldown has passed, then one probe is
+// let through (half-open). A healthy probe closes the breaker; a failing probe
+// opens it again. Staying paused longer than max_pause aborts the run.
+type breaker struct {
+	cfg      *config.CircuitBreakerConfig
+	cooldown time.Duration
+	maxPause time.Duration
+	pauseOn  map[int]bool
+
+	ctx   context.Context // Dispatch context, cancelled on abort
+	abort context.CancelFunc
+
+	mu             sync.Mutex
+	state          string
+	reason         string
+	window         []bool // Ring buffer of recent outcomes (true = failure)
+	windowPos      int
+	windowCount    int
+	windowFailures int
+	consecutive5xx int
+	openedAt       time.Time
+	pausedSince    time.Time
+	probing        bool
+	trips          int
+	changed        chan struct{}
+}
+
+// newBreaker creates a circuit breaker that cancels dispatch via abort
+func newBreaker(ctx context.Context, abort context.CancelFunc, cfg *config.CircuitBreakerConfig) *breaker {
+	cooldown, _ := time.ParseDuration(cfg.Cooldown)
+	maxPause, _ := time.ParseDuration(cfg.MaxPause)
+
+	pauseOn := make(map[int]bool)
+	for _, status := range cfg.PauseOn {
+		pauseOn[status] = true
+	}
+
+	return &breaker{
+		cfg:      cfg,
+		cooldown: cooldown,
+		maxPause: maxPause,
+		pauseOn:  pauseOn,
+		ctx:      ctx,
+		abort:    abort,
+		state:    BreakerClosed,
+		window:   make([]bool, cfg.Window),
+		changed:  make(chan struct{}),
+	}
+}
+
+// allow blocks while the breaker is open. It returns whether the request is a
+// half-open probe, and false for ok if the run was aborted or cancelled.
+func (b *breaker) allow() (probe bool, ok bool) {
+	for {
+		b.mu.Lock()
+
+		if b.state == BreakerOpen || b.state == BreakerHalfOpen {
+			if b.maxPause > 0 && time.Since(b.pausedSince) >= b.maxPause {
+				b.tripLocked(config.BreakerActionAbort, fmt.Sprintf("still paused after %v (%s)", b.maxPause, b.reason))
+			} else if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+				b.setStateLocked(BreakerHalfOpen)
+			}
+		}
+
+		switch b.state {
+		case BreakerClosed:
+			b.mu.Unlock()
+			return false, true
+		case BreakerAborted:
+			b.mu.Unlock()
+			return false, false
+		case BreakerHalfOpen:
+			if !b.probing {
+				b.probing = true
+				b.mu.Unlock()
+				return true, true
+			}
+		}
+
+		// Wait for a state change or the end of the cooldown
+		wait := b.cooldown - time.Since(b.openedAt)
+		if b.state == BreakerHalfOpen || wait < 10*time.Millisecond {
+			wait = 100 * time.Millisecond
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-changed:
+		case <-timer.C:
+		case <-b.ctx.Done():
+			timer.Stop()
+			return false, false
+		}
+		timer.Stop()
+	}
+}
+
+// record updates the breaker with the result of a request
+func (b *breaker) record(probe bool, result *request.RequestResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerAborted {
+		return
+	}
+
+	if probe {
+		b.probing = false
+		if b.healthy(result) {
+			b.closeLocked()
+		} else {
+			b.openedAt = time.Now()
+			b.setStateLocked(BreakerOpen)
+		}
+		return
+	}
+
+	// Results of requests that were in flight when the breaker opened
+	if b.state != BreakerClosed {
+		return
+	}
+
+	failed := !result.Success
+	if b.windowCount == len(b.window) {
+		if b.window[b.windowPos] {
+			b.windowFailures--
+		}
+	} else {
+		b.windowCount++
+	}
+	b.window[b.windowPos] = failed
+	b.windowPos = (b.windowPos + 1) % len(b.window)
+	if failed {
+		b.windowFailures++
+	}
+
+	if result.StatusCode >= 500 && result.StatusCode < 600 {
+		b.consecutive5xx++
+	} else {
+		b.consecutive5xx = 0
+	}
+
+	switch {
+	case b.pauseOn[result.StatusCode]:
+		b.tripLocked(config.BreakerActionPause, fmt.Sprintf("status %d", result.StatusCode))
+	case b.cfg.Consecutive5xx > 0 && b.consecutive5xx >= b.cfg.Consecutive5xx:
+		b.tripLocked(b.cfg.OnTrip, fmt.Sprintf("%d consecutive 5xx responses", b.consecutive5xx))
+	case b.cfg.FailureRate > 0 && b.windowCount == len(b.window):
+		rate := float64(b.windowFailures) / float64(b.windowCount) * 100
+		if rate > b.cfg.FailureRate {
+			b.tripLocked(b.cfg.OnTrip, fmt.Sprintf("failure rate %.1f%% over the last %d requests", rate, b.windowCount))
+		}
+	}
+}
+
+// healthy reports whether a probe shows the API is reachable again
+func (b *breaker) healthy(result *request.RequestResult) bool {
+	status := result.StatusCode
+	return status != 0 && status < 500 && !b.pauseOn[status]
+}
+
+// tripLocked aborts the run or opens the breaker
+func (b *breaker) tripLocked(action, reason string) {
+	b.trips++
+	b.reason = reason
+
+	if action == config.BreakerActionAbort {
+		b.setStateLocked(BreakerAborted)
+		b.abort()
+		return
+	}
+
+	b.openedAt = time.Now()
+	if b.pausedSince.IsZero() {
+		b.pausedSince = b.openedAt
+	}
+	b.setStateLocked(BreakerOpen)
+}
+
+// closeLocked closes the breaker and starts a fresh failure window
+func (b *breaker) closeLocked() {
+	b.windowPos = 0
+	b.windowCount = 0
+	b.windowFailures = 0
+	b.consecutive5xx = 0
+	b.pausedSince = time.Time{}
+	b.setStateLocked(BreakerClosed)
+}
+
+// setStateLocked changes the state and wakes up waiting workers
+func (b *breaker) setStateLocked(state string) {
+	b.state = state
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// status returns the current state, trip count and last trip reason
+func (b *breaker) status() (state string, trips int, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.trips, b.reason
+}
@@ -0,0 +1,194 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// pauseGate holds workers before their next row while a run is paused
+type pauseGate struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{} // Closed on resume
+}
+
+// pause closes the gate; it returns false if the gate was already closed
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return false
+	}
+	g.paused = true
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// resume opens the gate; it returns false if the gate was not closed
+func (g *pauseGate) resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		return false
+	}
+	g.paused = false
+	close(g.resumed)
+	return true
+}
+
+// isPaused reports whether the gate is closed
+func (g *pauseGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// wait blocks while the gate is closed. It returns false if the context is
+// cancelled while waiting.
+func (g *pauseGate) wait(ctx context.Context) bool {
+	g.mu.Lock()
+	paused, resumed := g.paused, g.resumed
+	g.mu.Unlock()
+
+	if !paused {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// workerPool tracks the workers of a running Run so the concurrency can be
+// changed while it runs
+type workerPool struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	target int
+	active int
+	closed bool   // Set once every worker stopped; no worker may start after that
+	spawn  func() // Starts one worker goroutine
+}
+
+// resize changes the number of workers. New workers start at once; surplus
+// workers stop after their current row.
+func (p *workerPool) resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.target = size
+	for !p.closed && p.active < p.target {
+		p.active++
+		p.wg.Add(1)
+		p.spawn()
+	}
+}
+
+// retire removes the calling worker from the pool if the pool has more
+// workers than its target. The worker must return when it reports true.
+func (p *workerPool) retire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active <= p.target {
+		return false
+	}
+	p.active--
+	p.wg.Done()
+	return true
+}
+
+// leave removes a worker that stopped because the run ended
+func (p *workerPool) leave() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.active--
+	if p.active == 0 {
+		p.closed = true
+	}
+	p.wg.Done()
+}
+
+// wait blocks until every worker stopped
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
+
+// Pause stops handing rows to workers. Requests already in flight finish and
+// are recorded; Resume continues with the next row. A pause outlasts the
+// current Run, so later Run calls start paused. It returns false if the
+// runner was already paused.
+func (r *Runner) Pause() bool {
+	return r.pause.pause()
+}
+
+// Resume continues sending rows after Pause. It returns false if the runner
+// was not paused.
+func (r *Runner) Resume() bool {
+	return r.pause.resume()
+}
+
+// Stop stops dispatch for good, as the first step of a graceful shutdown.
+// Requests in flight finish and are recorded; rows read but not yet sent are
+// recorded as not attempted and Run returns. Cancelling the Run context
+// afterwards aborts the requests still in flight.
+func (r *Runner) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// isStopped reports whether Stop was called
+func (r *Runner) isStopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetConcurrency changes the number of workers of the current and later Run
+// calls. A running Run starts new workers at once and stops surplus workers
+// after their current row. In ordered mode the reorder window of a running
+// Run is not enlarged, so it may limit the effective concurrency.
+func (r *Runner) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	r.controlMu.Lock()
+	defer r.controlMu.Unlock()
+
+	r.concurrency = concurrency
+	if r.reorderWindow < concurrency {
+		r.reorderWindow = concurrency
+	}
+	if r.pool != nil {
+		r.pool.resize(concurrency)
+	}
+}
+
+// SetRateLimit changes the request rate (requests per second, 0 = unlimited)
+// of the current and later Run calls. With a schedule it replaces the rate of
+// windows that don't set their own.
+func (r *Runner) SetRateLimit(rps float64) {
+	if r.schedule != nil {
+		r.schedule.setBaseRate(rps)
+		return
+	}
+	r.limiter.SetLimit(limitFor(rps))
+}
+
+// limitFor converts a rate in requests per second to a limiter limit
+func limitFor(rps float64) rate.Limit {
+	if rps <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(rps)
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"csvfire/internal/request"
+	"csvfire/internal/validator"
+)
+
+// DefaultReorderWindow is the default number of rows that may be in flight or
+// waiting for an earlier row when output order is preserved
+const DefaultReorderWindow = 1000
+
+// rowResult is the outcome of a processed task handed to the result callback
+type rowResult struct {
+	rowNum           int
+	validationResult *validator.ValidationResult
+	requestResult    *request.RequestResult
+}
+
+// reorderBuffer delivers results to the callback in dispatch order.
+//
+// Memory is capped by a window of slots: a slot is taken when a row is
+// dispatched and released only when its result is delivered. When a slow row
+// holds the oldest slot, the window fills up and dispatching stalls until that
+// row completes (bounded by its timeout and retries). Stalls are counted so
+// they show up in the run result.
+type reorderBuffer struct {
+	callback ResultCallback
+	slots    chan struct{}
+
+	mu      sync.Mutex
+	next    int64
+	pending map[int64]*rowResult
+
+	stalls    atomic.Int64
+	stallTime atomic.Int64 // nanoseconds
+}
+
+// newReorderBuffer creates a reorder buffer with the given window size
+func newReorderBuffer(window int, callback ResultCallback) *reorderBuffer {
+	return &reorderBuffer{
+		callback: callback,
+		slots:    make(chan struct{}, window),
+		pending:  make(map[int64]*rowResult),
+	}
+}
+
+// acquire takes a window slot for the next dispatched row, blocking while the
+// window is full. It returns false if the context is cancelled.
+func (b *reorderBuffer) acquire(ctx context.Context) bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	// Window is full: an earlier row is holding it open
+	b.stalls.Add(1)
+	start := time.Now()
+	defer func() { b.stallTime.Add(int64(time.Since(start))) }()
+
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// complete records the result of a row and delivers every result that is now
+// in order. A nil result marks a row without output (e.g. skipped).
+func (b *reorderBuffer) complete(seq int64, result *rowResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending[seq] = result
+	for {
+		next, ok := b.pending[b.next]
+		if !ok {
+			return
+		}
+		delete(b.pending, b.next)
+		b.next++
+		b.deliver(next)
+		<-b.slots
+	}
+}
+
+// flush delivers the remaining results in order, skipping rows that were never
+// processed (e.g. after cancellation)
+func (b *reorderBuffer) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seqs := make([]int64, 0, len(b.pending))
+	for seq := range b.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	for _, seq := range seqs {
+		b.deliver(b.pending[seq])
+		delete(b.pending, seq)
+	}
+}
+
+// deliver invokes the callback for a result
+func (b *reorderBuffer) deliver(result *rowResult) {
+	if result != nil && b.callback != nil {
+		b.callback(result.rowNum, result.validationResult, result.requestResult)
+	}
+}
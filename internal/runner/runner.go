@@ -4,13 +4,19 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/time/rate"
 
 	"csvfire/internal/config"
+	"csvfire/internal/metrics"
 	"csvfire/internal/request"
+	"csvfire/internal/tracing"
 	"csvfire/internal/validator"
 )
 
@@ -21,10 +27,21 @@ type Runner struct {
 	validator     *validator.Validator
 	renderer      *request.TemplateRenderer
 	client        *request.Client
-	limiter       *rate.Limiter
+	limiter       *rate.Limiter // Limit is rate.Inf when unlimited
 	concurrency   int
 	checkpoints   map[string]bool // For resume functionality
 	checkpointMu  sync.RWMutex
+	metrics       *metrics.Metrics
+	stats         runStats
+	orderedOutput bool
+	reorderWindow int
+	breaker       atomic.Pointer[breaker] // Circuit breaker of the current run
+	schedule      *scheduler              // Nil if sending is not restricted to time windows
+	pause         pauseGate
+	stop          chan struct{} // Closed by Stop
+	stopOnce      sync.Once
+	controlMu     sync.Mutex  // Guards concurrency, reorderWindow and pool
+	pool          *workerPool // Workers of the current run
 }
 
 // RunConfig holds configuration for running requests
@@ -33,6 +50,38 @@ type RunConfig struct {
 	RateLimit   float64 // requests per second
 	Timeout     time.Duration
 	Resume      bool
+	Metrics     *metrics.Metrics  // Optional Prometheus instrumentation
+	Transport   http.RoundTripper // Optional transport override (e.g. dry-run mock server)
+
+	// Optional wrapper applied to every request transport (e.g. recording)
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	OrderedOutput bool // Invoke the result callback in input order
+	ReorderWindow int  // Max rows in flight or buffered in ordered mode (0 = DefaultReorderWindow)
+}
+
+// runStats holds live counters updated concurrently by workers
+type runStats struct {
+	total   atomic.Int64
+	success atomic.Int64
+	failed  atomic.Int64
+	skipped atomic.Int64
+
+	notAttempted atomic.Int64
+}
+
+// Progress is a snapshot of the counters of a running Run
+type Progress struct {
+	TotalRows    int
+	SuccessRows  int
+	FailedRows   int
+	SkippedRows  int
+	NotAttempted int       // Rows read but not sent because dispatch stopped
+	BreakerState string    // Empty if no circuit breaker is configured
+	ResumeAt     time.Time // Next window start while waiting outside the schedule
+	Paused       bool
+	Concurrency  int
+	RateLimit    float64 // Current requests per second (0 = unlimited)
 }
 
 // RowTask represents a single row to be processed
@@ -40,17 +89,55 @@ type RowTask struct {
 	RowNumber int
 	Data      map[string]string
 	RequestID string
+
+	seq int64 // Dispatch order, used to restore order in ordered mode
 }
 
 // RunResult holds the results of processing
 type RunResult struct {
-	TotalRows     int
-	SuccessRows   int
-	FailedRows    int
-	SkippedRows   int
-	StartTime     time.Time
-	EndTime       time.Time
-	Duration      time.Duration
+	TotalRows    int
+	SuccessRows  int
+	FailedRows   int
+	SkippedRows  int
+	NotAttempted int // Rows read but not sent because dispatch stopped
+	StartTime    time.Time
+	EndTime      time.Time
+	Duration     time.Duration
+
+	// Ordered mode: how often and how long dispatching waited for a slow row
+	OrderStalls    int
+	OrderStallTime time.Duration
+
+	// Circuit breaker: set when the run was stopped before all rows were sent
+	Aborted      bool
+	AbortReason  string
+	BreakerTrips int
+
+	Stopped bool // Dispatch was stopped by Stop
+}
+
+// Add merges the result of a later Run call (e.g. the next ramp stage)
+func (r *RunResult) Add(other *RunResult) {
+	r.TotalRows += other.TotalRows
+	r.SuccessRows += other.SuccessRows
+	r.FailedRows += other.FailedRows
+	r.SkippedRows += other.SkippedRows
+	r.NotAttempted += other.NotAttempted
+	if r.StartTime.IsZero() || other.StartTime.Before(r.StartTime) {
+		r.StartTime = other.StartTime
+	}
+	if other.EndTime.After(r.EndTime) {
+		r.EndTime = other.EndTime
+	}
+	r.Duration = r.EndTime.Sub(r.StartTime)
+	r.OrderStalls += other.OrderStalls
+	r.OrderStallTime += other.OrderStallTime
+	r.BreakerTrips += other.BreakerTrips
+	if other.Aborted {
+		r.Aborted = true
+		r.AbortReason = other.AbortReason
+	}
+	r.Stopped = r.Stopped || other.Stopped
 }
 
 // ResultCallback is called for each processed row
@@ -69,11 +156,33 @@ func NewRunner(schema *config.Schema, requestConfig *config.RequestConfig, runCo
 
 	// Create HTTP client
 	client := request.NewClient(requestConfig, runConfig.Timeout)
+	client.SetMetrics(runConfig.Metrics)
+	if runConfig.Transport != nil {
+		client.SetTransport(runConfig.Transport)
+	}
+	if runConfig.WrapTransport != nil {
+		client.SetTransportWrapper(runConfig.WrapTransport)
+	}
 
-	// Create rate limiter
-	var limiter *rate.Limiter
-	if runConfig.RateLimit > 0 {
-		limiter = rate.NewLimiter(rate.Limit(runConfig.RateLimit), 1)
+	// The window must hold at least one row per worker
+	reorderWindow := runConfig.ReorderWindow
+	if reorderWindow <= 0 {
+		reorderWindow = DefaultReorderWindow
+	}
+	if reorderWindow < runConfig.Concurrency {
+		reorderWindow = runConfig.Concurrency
+	}
+
+	// Create rate limiter (unlimited runs get one too so SetRateLimit can limit them later)
+	limiter := rate.NewLimiter(limitFor(runConfig.RateLimit), 1)
+
+	// Time windows set the limiter rate
+	var schedule *scheduler
+	if requestConfig.Schedule != nil {
+		schedule, err = newScheduler(requestConfig.Schedule, runConfig.RateLimit, limiter)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &Runner{
@@ -85,6 +194,11 @@ func NewRunner(schema *config.Schema, requestConfig *config.RequestConfig, runCo
 		limiter:       limiter,
 		concurrency:   runConfig.Concurrency,
 		checkpoints:   make(map[string]bool),
+		metrics:       runConfig.Metrics,
+		orderedOutput: runConfig.OrderedOutput,
+		reorderWindow: reorderWindow,
+		schedule:      schedule,
+		stop:          make(chan struct{}),
 	}, nil
 }
 
@@ -98,38 +212,146 @@ func (r *Runner) LoadCheckpoints(checkpoints map[string]bool) {
 	}
 }
 
+// Progress returns a snapshot of the counters of the current run
+func (r *Runner) Progress() Progress {
+	progress := Progress{
+		TotalRows:   int(r.stats.total.Load()),
+		SuccessRows: int(r.stats.success.Load()),
+		FailedRows:  int(r.stats.failed.Load()),
+		SkippedRows: int(r.stats.skipped.Load()),
+		Paused:      r.pause.isPaused(),
+
+		NotAttempted: int(r.stats.notAttempted.Load()),
+	}
+	r.controlMu.Lock()
+	progress.Concurrency = r.concurrency
+	r.controlMu.Unlock()
+	if limit := r.limiter.Limit(); limit != rate.Inf {
+		progress.RateLimit = float64(limit)
+	}
+	if brk := r.breaker.Load(); brk != nil {
+		progress.BreakerState, _, _ = brk.status()
+	}
+	if r.schedule != nil {
+		progress.ResumeAt = r.schedule.waitingUntil()
+	}
+	return progress
+}
+
 // Run processes rows concurrently
 func (r *Runner) Run(ctx context.Context, rows <-chan RowTask, callback ResultCallback) *RunResult {
 	result := &RunResult{
 		StartTime: time.Now(),
 	}
 
-	// Create worker pool
-	taskChan := make(chan RowTask, r.concurrency*2) // Buffer to prevent blocking
-	var wg sync.WaitGroup
+	// Counters accumulate across Run calls (e.g. canary and ramp stages);
+	// the result covers this call only
+	before := r.Progress()
 
-	// Start workers
-	for i := 0; i < r.concurrency; i++ {
-		wg.Add(1)
-		go r.worker(ctx, taskChan, callback, result, &wg)
+	// Stopping dispatch (Stop, user cancellation or circuit breaker abort)
+	// lets in-flight requests finish and be recorded
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	go func() {
+		select {
+		case <-r.stop:
+			stopDispatch()
+		case <-dispatchCtx.Done():
+		}
+	}()
+
+	var brk *breaker
+	if r.requestConfig.CircuitBreaker != nil {
+		brk = newBreaker(dispatchCtx, stopDispatch, r.requestConfig.CircuitBreaker)
 	}
+	r.breaker.Store(brk)
+
+	r.controlMu.Lock()
 
-	// Feed tasks to workers
+	// Results are reordered before reaching the callback in ordered mode
+	var order *reorderBuffer
+	if r.orderedOutput {
+		order = newReorderBuffer(r.reorderWindow, callback)
+	}
+
+	// Create worker pool (resized by SetConcurrency while running)
+	taskChan := make(chan RowTask, r.concurrency*2) // Buffer to prevent blocking
+	pool := &workerPool{}
+	pool.spawn = func() {
+		go r.worker(ctx, dispatchCtx, taskChan, callback, order, pool)
+	}
+	pool.resize(r.concurrency)
+	r.pool = pool
+	r.controlMu.Unlock()
+
+	// Feed tasks to workers. Once dispatch stops no more rows are taken, so
+	// rows left in the rows channel were never read by this run.
 	go func() {
 		defer close(taskChan)
-		for task := range rows {
+		var seq int64
+		for {
+			if order != nil && !order.acquire(dispatchCtx) {
+				return
+			}
+
+			var task RowTask
+			select {
+			case next, ok := <-rows:
+				if !ok {
+					return
+				}
+				task = next
+			case <-dispatchCtx.Done():
+				return
+			}
+			task.seq = seq
+			seq++
+			r.stats.total.Add(1)
+			r.metrics.IncRowsRead()
+
 			select {
 			case taskChan <- task:
-				result.TotalRows++
-			case <-ctx.Done():
+			case <-dispatchCtx.Done():
+				r.abandon(task, callback, order)
 				return
 			}
 		}
 	}()
 
 	// Wait for all workers to complete
-	wg.Wait()
+	pool.wait()
+	r.controlMu.Lock()
+	r.pool = nil
+	r.controlMu.Unlock()
+
+	// Rows queued for workers when dispatch stopped are recorded as not attempted
+	for task := range taskChan {
+		r.abandon(task, callback, order)
+	}
+
+	if order != nil {
+		order.flush()
+		result.OrderStalls = int(order.stalls.Load())
+		result.OrderStallTime = time.Duration(order.stallTime.Load())
+	}
+
+	if brk != nil {
+		state, trips, reason := brk.status()
+		result.BreakerTrips = trips
+		if state == BreakerAborted {
+			result.Aborted = true
+			result.AbortReason = reason
+		}
+	}
 
+	// Rows dispatched but dropped after dispatch stopped don't count as processed
+	progress := r.Progress()
+	result.SuccessRows = progress.SuccessRows - before.SuccessRows
+	result.FailedRows = progress.FailedRows - before.FailedRows
+	result.SkippedRows = progress.SkippedRows - before.SkippedRows
+	result.NotAttempted = progress.NotAttempted - before.NotAttempted
+	result.TotalRows = result.SuccessRows + result.FailedRows + result.SkippedRows + result.NotAttempted
+	result.Stopped = r.isStopped()
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
@@ -137,30 +359,125 @@ func (r *Runner) Run(ctx context.Context, rows <-chan RowTask, callback ResultCa
 }
 
 // worker processes individual tasks
-func (r *Runner) worker(ctx context.Context, tasks <-chan RowTask, callback ResultCallback, result *RunResult, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+func (r *Runner) worker(ctx, dispatchCtx context.Context, tasks <-chan RowTask, callback ResultCallback, order *reorderBuffer, pool *workerPool) {
 	for task := range tasks {
-		select {
-		case <-ctx.Done():
+		if !r.dispatch(ctx, dispatchCtx, task, callback, order) {
+			r.abandon(task, callback, order)
+			break
+		}
+
+		// Stop after this row if the concurrency was lowered
+		if pool.retire() {
 			return
-		default:
-			r.processTask(ctx, task, callback, result)
 		}
 	}
+	pool.leave()
+}
+
+// dispatch processes a task and passes its result on. It returns false
+// without processing the task once dispatch was stopped.
+func (r *Runner) dispatch(ctx, dispatchCtx context.Context, task RowTask, callback ResultCallback, order *reorderBuffer) bool {
+	if dispatchCtx.Err() != nil {
+		return false
+	}
+
+	// Hold the row while paused and until the next time window opens
+	if !r.pause.wait(dispatchCtx) {
+		return false
+	}
+	if r.schedule != nil && !r.schedule.wait(dispatchCtx) {
+		return false
+	}
+
+	r.deliver(task, r.processTask(ctx, dispatchCtx, task), callback, order)
+	return true
+}
+
+// deliver passes the result of a task to the callback, through the reorder
+// buffer in ordered mode
+func (r *Runner) deliver(task RowTask, result *rowResult, callback ResultCallback, order *reorderBuffer) {
+	if order != nil {
+		order.complete(task.seq, result)
+	} else if result != nil && callback != nil {
+		callback(result.rowNum, result.validationResult, result.requestResult)
+	}
+}
+
+// abandon records a task that was read but never sent
+func (r *Runner) abandon(task RowTask, callback ResultCallback, order *reorderBuffer) {
+	r.deliver(task, r.notAttempted(task, "dispatch stopped before the request was sent"), callback, order)
+}
+
+// Discard records the rows no Run call read, e.g. the rest of the CSV after a
+// run was stopped, as not attempted so that every input row is accounted for.
+// It returns the number of rows recorded.
+func (r *Runner) Discard(rows <-chan RowTask, callback ResultCallback) int {
+	count := 0
+	for task := range rows {
+		r.stats.total.Add(1)
+		r.deliver(task, r.notAttempted(task, "run stopped before the row was read"), callback, nil)
+		count++
+	}
+	return count
+}
+
+// notAttempted counts a row that was not sent and returns its result
+func (r *Runner) notAttempted(task RowTask, detail string) *rowResult {
+	r.stats.notAttempted.Add(1)
+	return &rowResult{
+		rowNum: task.RowNumber,
+		// The row was not validated; its raw values are kept for the failed row export
+		validationResult: &validator.ValidationResult{
+			Valid: true,
+			Data:  task.Data,
+			Raw:   task.Data,
+		},
+		requestResult: &request.RequestResult{
+			RequestID:     task.RequestID,
+			Success:       false,
+			ErrorCategory: request.NotAttemptedCategory,
+			ErrorDetail:   detail,
+		},
+	}
 }
 
-// processTask processes a single task
-func (r *Runner) processTask(ctx context.Context, task RowTask, callback ResultCallback, result *RunResult) {
+// processTask processes a single task and returns its result, or nil if the
+// row was already processed by an earlier run. dispatchCtx ends the waits
+// before the request is sent.
+func (r *Runner) processTask(ctx, dispatchCtx context.Context, task RowTask) *rowResult {
+	ctx, span := tracing.Start(ctx, "row",
+		attribute.Int("csvfire.row", task.RowNumber),
+		attribute.String("csvfire.request_id", task.RequestID))
+	defer span.End()
+
 	// Rate limiting
-	if r.limiter != nil {
-		if err := r.limiter.Wait(ctx); err != nil {
-			return // Context cancelled
+	for r.limiter.Limit() != rate.Inf {
+		_, waitSpan := tracing.Start(ctx, "limiter.wait")
+		waitStart := time.Now()
+		err := r.limiter.Wait(dispatchCtx)
+		waitSpan.End()
+		if err != nil {
+			return r.notAttempted(task, "dispatch stopped while waiting for the rate limiter")
+		}
+		r.metrics.ObserveLimiterWait(time.Since(waitStart))
+
+		// The window may have closed while waiting for the limiter
+		if r.schedule == nil || r.schedule.open() {
+			break
+		}
+		if !r.schedule.wait(dispatchCtx) {
+			return r.notAttempted(task, "dispatch stopped while waiting for the time window")
 		}
 	}
 
 	// Validate the row
+	_, validateSpan := tracing.Start(ctx, "validate")
 	validationResult := r.validator.ValidateRow(task.RowNumber, task.Data)
+	validateSpan.SetAttributes(
+		attribute.Bool("csvfire.valid", validationResult.Valid),
+		attribute.Int("csvfire.validation_errors", len(validationResult.Errors)))
+	validateSpan.End()
+	r.metrics.IncRowsValidated(validationResult.Valid)
 	
 	var requestResult *request.RequestResult
 
@@ -170,12 +487,20 @@ func (r *Runner) processTask(ctx context.Context, task RowTask, callback ResultC
 		
 		// Check if this request was already processed (resume functionality)
 		if r.isAlreadyProcessed(requestHash) {
-			result.SkippedRows++
-			return
+			span.SetAttributes(attribute.Bool("csvfire.skipped", true))
+			r.stats.skipped.Add(1)
+			r.metrics.IncRowsSkipped()
+			return nil
 		}
 
 		// Render request template
-		requestData, err := r.renderer.Render(validationResult.Data)
+		_, renderSpan := tracing.Start(ctx, "render")
+		requestData, err := r.renderer.Render(validationResult)
+		if err != nil {
+			renderSpan.RecordError(err)
+			renderSpan.SetStatus(codes.Error, "template_error")
+		}
+		renderSpan.End()
 		if err != nil {
 			// Create a dummy request result for template errors
 			requestResult = &request.RequestResult{
@@ -184,22 +509,34 @@ func (r *Runner) processTask(ctx context.Context, task RowTask, callback ResultC
 				ErrorCategory: "template_error",
 				ErrorDetail:   err.Error(),
 			}
+			r.stats.failed.Add(1)
+			r.recordBreaker(false, requestResult)
 		} else {
+			// Wait while the circuit breaker is open; stop if the run was aborted
+			probe := false
+			if brk := r.breaker.Load(); brk != nil {
+				var ok bool
+				if probe, ok = brk.allow(); !ok {
+					return r.notAttempted(task, "run aborted by the circuit breaker")
+				}
+			}
+
 			// Execute HTTP request
 			requestData.Hash = requestHash
 			requestResult = r.client.Execute(ctx, requestData, task.RequestID)
+			r.recordBreaker(probe, requestResult)
 			
 			// Mark as processed if successful
 			if requestResult.Success {
 				r.markAsProcessed(requestHash)
-				result.SuccessRows++
+				r.stats.success.Add(1)
 			} else {
-				result.FailedRows++
+				r.stats.failed.Add(1)
 			}
 		}
 	} else {
 		// Validation failed
-		result.FailedRows++
+		r.stats.failed.Add(1)
 		requestResult = &request.RequestResult{
 			RequestID:     task.RequestID,
 			Success:       false,
@@ -208,9 +545,21 @@ func (r *Runner) processTask(ctx context.Context, task RowTask, callback ResultC
 		}
 	}
 
-	// Call callback with results
-	if callback != nil {
-		callback(task.RowNumber, validationResult, requestResult)
+	if requestResult != nil && !requestResult.Success {
+		span.SetStatus(codes.Error, requestResult.ErrorCategory)
+	}
+
+	return &rowResult{
+		rowNum:           task.RowNumber,
+		validationResult: validationResult,
+		requestResult:    requestResult,
+	}
+}
+
+// recordBreaker feeds a request result to the circuit breaker of the current run
+func (r *Runner) recordBreaker(probe bool, requestResult *request.RequestResult) {
+	if brk := r.breaker.Load(); brk != nil {
+		brk.record(probe, requestResult)
 	}
 }
 
@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"csvfire/internal/config"
+)
+
+// scheduleWindow is a parsed daily sending window
+type scheduleWindow struct {
+	start int // Minutes after midnight
+	end   int
+	rate  float64
+	days  map[time.Weekday]bool // Days the window starts on (nil = every day)
+}
+
+// scheduler holds requests outside the configured time windows and applies
+// the rate of the current window to the shared limiter
+type scheduler struct {
+	location *time.Location
+	windows  []scheduleWindow
+	baseRate float64
+	limiter  *rate.Limiter
+	mu       sync.Mutex
+	resumeAt time.Time // Start of the next window while waiting
+	current  int       // Index of the window whose rate is applied (-1 = none)
+}
+
+// newScheduler creates a scheduler from a validated schedule config
+func newScheduler(cfg *config.ScheduleConfig, baseRate float64, limiter *rate.Limiter) (*scheduler, error) {
+	location, err := cfg.Location()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule timezone: %w", err)
+	}
+
+	s := &scheduler{
+		location: location,
+		baseRate: baseRate,
+		limiter:  limiter,
+		current:  -1,
+	}
+
+	for _, window := range cfg.Windows {
+		start, err := config.ParseClock(window.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := config.ParseClock(window.End)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed := scheduleWindow{start: start, end: end, rate: window.Rate}
+		if len(window.Days) > 0 {
+			parsed.days = make(map[time.Weekday]bool)
+			for _, name := range window.Days {
+				day, err := config.ParseWeekday(name)
+				if err != nil {
+					return nil, err
+				}
+				parsed.days[day] = true
+			}
+		}
+		s.windows = append(s.windows, parsed)
+	}
+
+	return s, nil
+}
+
+// wait blocks until the current time is inside a window, then applies the
+// window's rate. It returns false if the context is cancelled while waiting.
+func (s *scheduler) wait(ctx context.Context) bool {
+	for {
+		now := time.Now().In(s.location)
+		index, next := s.lookup(now)
+		if index >= 0 {
+			s.enter(index)
+			return true
+		}
+
+		s.mu.Lock()
+		s.resumeAt = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// open reports whether the current time is inside a window, applying the
+// window's rate if so
+func (s *scheduler) open() bool {
+	index, _ := s.lookup(time.Now().In(s.location))
+	if index < 0 {
+		return false
+	}
+	s.enter(index)
+	return true
+}
+
+// enter applies the rate of a window when the active window changes
+func (s *scheduler) enter(index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resumeAt = time.Time{}
+	if s.current == index {
+		return
+	}
+	s.current = index
+
+	limit := limitFor(s.baseRate)
+	if r := s.windows[index].rate; r > 0 {
+		limit = rate.Limit(r)
+	}
+	s.limiter.SetLimit(limit)
+}
+
+// setBaseRate changes the rate of windows without their own rate
+func (s *scheduler) setBaseRate(rps float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.baseRate = rps
+	if s.current >= 0 && s.windows[s.current].rate <= 0 {
+		s.limiter.SetLimit(limitFor(rps))
+	}
+}
+
+// lookup returns the index of the window containing now, or -1 and the
+// start of the next window
+func (s *scheduler) lookup(now time.Time) (int, time.Time) {
+	minute := now.Hour()*60 + now.Minute()
+	today := now.Weekday()
+	yesterday := (today + 6) % 7
+
+	for i, window := range s.windows {
+		if window.start < window.end {
+			if window.allows(today) && minute >= window.start && minute < window.end {
+				return i, time.Time{}
+			}
+			continue
+		}
+
+		// Window crossing midnight: the evening part belongs to today,
+		// the morning part to the window that started yesterday
+		if window.allows(today) && minute >= window.start {
+			return i, time.Time{}
+		}
+		if window.allows(yesterday) && minute < window.end {
+			return i, time.Time{}
+		}
+	}
+
+	return -1, s.nextStart(now)
+}
+
+// nextStart returns the earliest window start after now
+func (s *scheduler) nextStart(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location)
+
+	var next time.Time
+	for offset := 0; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, window := range s.windows {
+			if !window.allows(day.Weekday()) {
+				continue
+			}
+			start := day.Add(time.Duration(window.start) * time.Minute)
+			if start.After(now) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+
+	return now.Add(time.Minute)
+}
+
+// allows reports whether the window may start on a weekday
+func (w scheduleWindow) allows(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// waitingUntil returns the next resume time while waiting outside the windows
+func (s *scheduler) waitingUntil() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resumeAt
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+
+	"csvfire/internal/config"
+	"csvfire/internal/history"
+)
+
+// writeFailedRows writes the rows of a job that are still failed. Each
+// execution exports its own failures, so the latest failure of a row wins and
+// rows that succeeded later are left out.
+func writeFailedRows(w io.Writer, job *Job) (int, error) {
+	// A job that never ran has no sent log and no failed rows
+	outcomes, _ := history.ReadOutcomes(job.logDir())
+
+	var header []string
+	sourceColumn := -1
+	latest := make(map[int][]string)
+
+	for _, filename := range job.failedFiles() {
+		file, err := os.Open(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to open failed rows: %w", err)
+		}
+
+		csvReader := csv.NewReader(file)
+		csvReader.FieldsPerRecord = -1
+		records, err := csvReader.ReadAll()
+		file.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read failed rows: %w", err)
+		}
+		if len(records) == 0 {
+			continue
+		}
+
+		if header == nil {
+			header = records[0]
+			sourceColumn = slices.Index(header, config.SourceRowColumn)
+			if sourceColumn < 0 {
+				return 0, fmt.Errorf("failed rows have no %s column", config.SourceRowColumn)
+			}
+		}
+
+		for _, record := range records[1:] {
+			if sourceColumn >= len(record) {
+				continue
+			}
+			row, err := strconv.Atoi(record[sourceColumn])
+			if err != nil {
+				continue
+			}
+			latest[row] = record
+		}
+	}
+
+	if header == nil {
+		return 0, nil
+	}
+
+	rows := make([]int, 0, len(latest))
+	for row := range latest {
+		if outcome, ok := outcomes[row]; ok && outcome.Success {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := writer.Write(latest[row]); err != nil {
+			return 0, err
+		}
+	}
+	writer.Flush()
+
+	return len(rows), writer.Error()
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JobState is the lifecycle state of a job
+type JobState string
+
+// Job states
+const (
+	JobCreated   JobState = "created"   // Uploaded, waiting to be started
+	JobQueued    JobState = "queued"    // Waiting for a free execution slot
+	JobRunning   JobState = "running"   // Sending rows
+	JobPaused    JobState = "paused"    // Stopped; resume continues with the unsent rows
+	JobCompleted JobState = "completed" // Every row was processed
+	JobFailed    JobState = "failed"    // Stopped by an error (see Job.Error)
+	JobCancelled JobState = "cancelled" // Stopped for good by a client
+)
+
+// Terminal reports whether a job in this state will never run again
+func (s JobState) Terminal() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCancelled
+}
+
+// Files stored in a job directory
+const (
+	jobFile           = "job.json"
+	schemaFile        = "schema.yaml"
+	requestFile       = "request.yaml"
+	csvFile           = "input.csv"
+	mockFile          = "mock.yaml"
+	logDirName        = "logs"
+	failedFilePattern = "failed_rows.%d.csv" // Failed rows of each execution
+)
+
+// JobOptions are the execution options of a job
+type JobOptions struct {
+	Concurrency int     `json:"concurrency"`
+	RateLimit   float64 `json:"rate_limit,omitempty"` // Requests per second (0 = unlimited)
+	Timeout     string  `json:"timeout"`
+	DryRun      bool    `json:"dry_run,omitempty"` // Send to the built-in mock server
+}
+
+// JobProgress counts the rows of a job across all of its executions
+type JobProgress struct {
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	SuccessRows   int        `json:"success_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	SkippedRows   int        `json:"skipped_rows"`
+	NotAttempted  int        `json:"not_attempted"` // Rows the last execution read but didn't send; sent again on resume
+	BreakerState  string     `json:"breaker_state,omitempty"`
+	ResumeAt      *time.Time `json:"resume_at,omitempty"` // Next schedule window while waiting
+}
+
+// Job is a CSV run submitted to the server
+type Job struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name,omitempty"`
+	State      JobState    `json:"state"`
+	Options    JobOptions  `json:"options"`
+	Progress   JobProgress `json:"progress"`
+	Error      string      `json:"error,omitempty"`
+	Executions int         `json:"executions"` // Number of times the job was started or resumed
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+
+	dir string
+}
+
+// path returns the path of a file in the job directory
+func (j *Job) path(name string) string {
+	return filepath.Join(j.dir, name)
+}
+
+// logDir returns the run directory holding the job's logs and manifest
+func (j *Job) logDir() string {
+	return j.path(logDirName)
+}
+
+// failedFiles returns the failed row exports of the job's executions in order
+func (j *Job) failedFiles() []string {
+	files := make([]string, 0, j.Executions)
+	for execution := 1; execution <= j.Executions; execution++ {
+		files = append(files, j.path(fmt.Sprintf(failedFilePattern, execution)))
+	}
+	return files
+}
+
+// save writes job.json, replacing it atomically
+func (j *Job) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	filename := j.path(jobFile)
+	tmpFile := filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := os.Rename(tmpFile, filename); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+
+	return nil
+}
+
+// loadJob reads a job from its directory
+func loadJob(dir string) (*Job, error) {
+	data, err := os.ReadFile(filepath.Join(dir, jobFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job: %w", err)
+	}
+	job.dir = dir
+
+	return &job, nil
+}
@@ -0,0 +1,562 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"csvfire/internal/config"
+	"csvfire/internal/reader"
+	"csvfire/internal/runner"
+)
+
+// Errors returned by job operations
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrInvalidJobState = errors.New("operation not allowed in the current job state")
+	ErrInvalidOptions  = errors.New("invalid job options")
+)
+
+// Manager owns the jobs of the server, persists them under a data directory
+// and executes them with a limited number of concurrent jobs
+type Manager struct {
+	jobsDir    string
+	ctx        context.Context // Cancelled on shutdown
+	cancel     context.CancelFunc
+	slots      chan struct{}
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	executions map[string]*execution // Jobs queued or running
+	wg         sync.WaitGroup
+	stopping   bool // Set by Shutdown; executions end without changing the job state
+}
+
+// execution is the in-memory state of a queued or running job
+type execution struct {
+	cancel context.CancelFunc
+	stopAs JobState       // State to enter when stopped on request (paused or cancelled)
+	runner *runner.Runner // Nil while queued
+	base   JobProgress    // Rows recorded by earlier executions
+}
+
+// NewManager loads the jobs persisted in dataDir. Jobs that were queued or
+// running when the server stopped are started again by Recover.
+func NewManager(dataDir string, maxJobs int) (*Manager, error) {
+	if maxJobs <= 0 {
+		maxJobs = 1
+	}
+
+	jobsDir := filepath.Join(dataDir, "jobs")
+	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		jobsDir:    jobsDir,
+		ctx:        ctx,
+		cancel:     cancel,
+		slots:      make(chan struct{}, maxJobs),
+		jobs:       make(map[string]*Job),
+		executions: make(map[string]*execution),
+	}
+
+	entries, err := os.ReadDir(jobsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		job, err := loadJob(filepath.Join(jobsDir, entry.Name()))
+		if err != nil {
+			// A job whose upload never completed has no job.json
+			continue
+		}
+		m.jobs[job.ID] = job
+	}
+
+	return m, nil
+}
+
+// Recover starts the jobs interrupted by a previous shutdown and returns their IDs
+func (m *Manager) Recover() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var recovered []string
+	for _, job := range m.jobs {
+		if job.State == JobQueued || job.State == JobRunning {
+			m.launchLocked(job)
+			recovered = append(recovered, job.ID)
+		}
+	}
+	sort.Strings(recovered)
+	return recovered
+}
+
+// JobUpload holds the files and options of a new job
+type JobUpload struct {
+	Name    string
+	Options JobOptions
+	Schema  io.Reader
+	Request io.Reader
+	CSV     io.Reader
+	Mock    io.Reader // Optional dry-run mock script
+}
+
+// Create stores an uploaded job after validating its configuration and CSV header
+func (m *Manager) Create(upload JobUpload) (*Job, error) {
+	if upload.Schema == nil || upload.Request == nil || upload.CSV == nil {
+		return nil, fmt.Errorf("schema, request and csv files are required")
+	}
+	if upload.Options.Concurrency <= 0 {
+		upload.Options.Concurrency = 8
+	}
+	if upload.Options.Timeout == "" {
+		upload.Options.Timeout = "10s"
+	}
+	if _, err := time.ParseDuration(upload.Options.Timeout); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if upload.Options.RateLimit < 0 {
+		return nil, fmt.Errorf("rate limit must not be negative")
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Name:      upload.Name,
+		State:     JobCreated,
+		Options:   upload.Options,
+		CreatedAt: time.Now(),
+		dir:       filepath.Join(m.jobsDir, id),
+	}
+
+	if err := os.MkdirAll(job.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	files := []struct {
+		name   string
+		source io.Reader
+	}{
+		{schemaFile, upload.Schema},
+		{requestFile, upload.Request},
+		{csvFile, upload.CSV},
+		{mockFile, upload.Mock},
+	}
+	for _, file := range files {
+		if file.source == nil {
+			continue
+		}
+		if err := writeFile(job.path(file.name), file.source); err != nil {
+			os.RemoveAll(job.dir)
+			return nil, err
+		}
+	}
+
+	totalRows, err := checkJobFiles(job)
+	if err != nil {
+		os.RemoveAll(job.dir)
+		return nil, err
+	}
+	job.Progress.TotalRows = totalRows
+
+	if err := job.save(); err != nil {
+		os.RemoveAll(job.dir)
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	return m.snapshot(job), nil
+}
+
+// Get returns a snapshot of a job including the progress of a running execution
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return m.snapshot(job), nil
+}
+
+// List returns snapshots of all jobs, newest first
+func (m *Manager) List() []*Job {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.Unlock()
+
+	snapshots := make([]*Job, len(jobs))
+	for i, job := range jobs {
+		snapshots[i] = m.snapshot(job)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots
+}
+
+// Start queues a created job for execution
+func (m *Manager) Start(id string) (*Job, error) {
+	return m.transition(id, func(job *Job) error {
+		if job.State != JobCreated {
+			return ErrInvalidJobState
+		}
+		m.launchLocked(job)
+		return nil
+	})
+}
+
+// Pause stops sending new rows of a queued or running job. A running job
+// keeps its execution: requests in flight finish and Resume continues with
+// the next row. A queued job is stopped; Resume queues it again.
+func (m *Manager) Pause(id string) (*Job, error) {
+	return m.transition(id, func(job *Job) error {
+		exec, ok := m.executions[id]
+		if !ok || exec.stopAs != "" || job.State == JobPaused {
+			return ErrInvalidJobState
+		}
+		if exec.runner != nil {
+			exec.runner.Pause()
+		} else {
+			exec.stopAs = JobPaused
+			exec.cancel()
+		}
+		job.State = JobPaused
+		return job.save()
+	})
+}
+
+// Resume continues a paused job with the rows not yet recorded
+func (m *Manager) Resume(id string) (*Job, error) {
+	return m.transition(id, func(job *Job) error {
+		if job.State != JobPaused {
+			return ErrInvalidJobState
+		}
+		exec, ok := m.executions[id]
+		switch {
+		case ok && exec.stopAs == "" && exec.runner != nil:
+			exec.runner.Resume()
+			job.State = JobRunning
+			return job.save()
+		case ok:
+			return ErrInvalidJobState // Still stopping
+		}
+		job.Error = ""
+		m.launchLocked(job)
+		return nil
+	})
+}
+
+// JobUpdate changes the execution options of a job; nil fields are kept
+type JobUpdate struct {
+	Concurrency *int     `json:"concurrency,omitempty"`
+	RateLimit   *float64 `json:"rate_limit,omitempty"` // Requests per second (0 = unlimited)
+}
+
+// Update changes the concurrency or rate of a job. A running or paused
+// execution applies them at once; later executions start with them.
+func (m *Manager) Update(id string, update JobUpdate) (*Job, error) {
+	if update.Concurrency != nil && *update.Concurrency <= 0 {
+		return nil, fmt.Errorf("%w: concurrency must be positive", ErrInvalidOptions)
+	}
+	if update.RateLimit != nil && *update.RateLimit < 0 {
+		return nil, fmt.Errorf("%w: rate limit must not be negative", ErrInvalidOptions)
+	}
+
+	return m.transition(id, func(job *Job) error {
+		if job.State.Terminal() {
+			return ErrInvalidJobState
+		}
+
+		var live *runner.Runner
+		if exec, ok := m.executions[id]; ok {
+			live = exec.runner
+		}
+		if update.Concurrency != nil {
+			job.Options.Concurrency = *update.Concurrency
+			if live != nil {
+				live.SetConcurrency(*update.Concurrency)
+			}
+		}
+		if update.RateLimit != nil {
+			job.Options.RateLimit = *update.RateLimit
+			if live != nil {
+				live.SetRateLimit(*update.RateLimit)
+			}
+		}
+		return job.save()
+	})
+}
+
+// Cancel stops a job for good
+func (m *Manager) Cancel(id string) (*Job, error) {
+	return m.transition(id, func(job *Job) error {
+		if job.State.Terminal() {
+			return ErrInvalidJobState
+		}
+		if exec, ok := m.executions[id]; ok {
+			exec.stopAs = JobCancelled
+			exec.cancel()
+			return nil
+		}
+		m.finishLocked(job, JobCancelled)
+		return job.save()
+	})
+}
+
+// LogFile returns the path of a log file of a job
+func (m *Manager) LogFile(id, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return "", ErrJobNotFound
+	}
+	return filepath.Join(job.logDir(), name), nil
+}
+
+// Shutdown stops all executions and waits for their logs to be written.
+// Executions stop sending at once; requests in flight may finish until ctx is
+// done and are aborted after that. Interrupted jobs keep their state and are
+// resumed by Recover on the next start.
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	m.stopping = true
+	for _, exec := range m.executions {
+		if exec.runner != nil {
+			exec.runner.Stop()
+		}
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	m.cancel()
+	<-done
+}
+
+// transition applies a state change to a job under the manager lock
+func (m *Manager) transition(id string, change func(job *Job) error) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	err := change(job)
+	m.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return m.snapshot(job), nil
+}
+
+// launchLocked queues a job and starts its execution in the background
+func (m *Manager) launchLocked(job *Job) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.executions[job.ID] = &execution{cancel: cancel}
+
+	job.State = JobQueued
+	job.save()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.execute(ctx, job)
+	}()
+}
+
+// execute waits for an execution slot, runs the job and records how it ended
+func (m *Manager) execute(ctx context.Context, job *Job) {
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		m.stopped(job, nil)
+		return
+	}
+
+	m.mu.Lock()
+	if m.stopping {
+		m.mu.Unlock()
+		m.stopped(job, nil)
+		return
+	}
+	now := time.Now()
+	job.State = JobRunning
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	job.Executions++
+	job.save()
+	m.mu.Unlock()
+
+	m.stopped(job, m.run(ctx, job))
+}
+
+// stopped records the outcome of an execution
+func (m *Manager) stopped(job *Job, runErr error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exec := m.executions[job.ID]
+	delete(m.executions, job.ID)
+
+	job.Progress = liveProgress(job.Progress, exec)
+
+	switch {
+	case exec.stopAs != "":
+		if exec.stopAs == JobCancelled {
+			m.finishLocked(job, JobCancelled)
+		} else {
+			job.State = exec.stopAs
+		}
+	case errors.Is(runErr, errAborted):
+		// The circuit breaker stopped the job; it can be resumed once the API recovered
+		job.State = JobPaused
+		job.Error = runErr.Error()
+	case runErr != nil:
+		job.Error = runErr.Error()
+		m.finishLocked(job, JobFailed)
+	case m.stopping:
+		// Server shutdown: keep the state so the job is resumed on restart
+		// (or stays paused)
+	default:
+		m.finishLocked(job, JobCompleted)
+	}
+
+	job.save()
+}
+
+// finishLocked moves a job into a terminal state
+func (m *Manager) finishLocked(job *Job, state JobState) {
+	now := time.Now()
+	job.State = state
+	job.FinishedAt = &now
+}
+
+// snapshot returns a copy of a job with the live progress of its execution
+func (m *Manager) snapshot(job *Job) *Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *job
+	copied.Progress = liveProgress(job.Progress, m.executions[job.ID])
+	return &copied
+}
+
+// liveProgress combines the rows of earlier executions with the counters of
+// a running one
+func liveProgress(progress JobProgress, exec *execution) JobProgress {
+	if exec == nil || exec.runner == nil {
+		return progress
+	}
+
+	live := exec.runner.Progress()
+	progress.SuccessRows = exec.base.SuccessRows + live.SuccessRows
+	progress.FailedRows = exec.base.FailedRows + live.FailedRows
+	progress.SkippedRows = exec.base.SkippedRows + live.SkippedRows
+	progress.NotAttempted = live.NotAttempted
+	progress.ProcessedRows = progress.SuccessRows + progress.FailedRows + progress.SkippedRows + progress.NotAttempted
+	progress.BreakerState = live.BreakerState
+	progress.ResumeAt = nil
+	if !live.ResumeAt.IsZero() {
+		resumeAt := live.ResumeAt
+		progress.ResumeAt = &resumeAt
+	}
+	return progress
+}
+
+// checkJobFiles validates the uploaded configs and CSV header and counts the rows
+func checkJobFiles(job *Job) (int, error) {
+	// Only the uploaded files may be read, not other files of the server
+	files, err := config.SchemaFiles(job.path(schemaFile))
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		if !filepath.IsLocal(file) {
+			return 0, fmt.Errorf("uploaded schemas can't refer to files outside the job: %s", file)
+		}
+	}
+
+	schema, err := config.LoadSchema(job.path(schemaFile))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := config.LoadRequestConfig(job.path(requestFile)); err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(job.path(mockFile)); err == nil {
+		if _, err := config.LoadMockConfig(job.path(mockFile)); err != nil {
+			return 0, err
+		}
+	}
+
+	totalRows := 0
+	err = reader.NewCSVReader(schema, job.path(csvFile)).ForEachRow(func(task runner.RowTask) bool {
+		totalRows++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return totalRows, nil
+}
+
+// writeFile stores an uploaded file
+func writeFile(path string, source io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, source); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// newJobID returns a sortable, unique job ID
+func newJobID() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
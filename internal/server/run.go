@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"csvfire/internal/config"
+	"csvfire/internal/history"
+	"csvfire/internal/logger"
+	"csvfire/internal/mock"
+	"csvfire/internal/reader"
+	"csvfire/internal/request"
+	"csvfire/internal/runner"
+)
+
+// errAborted reports that the circuit breaker stopped an execution
+var errAborted = errors.New("aborted by circuit breaker")
+
+// run executes the rows of a job that weren't recorded by earlier executions
+func (m *Manager) run(ctx context.Context, job *Job) error {
+	schema, err := config.LoadSchema(job.path(schemaFile))
+	if err != nil {
+		return err
+	}
+	requestConfig, err := config.LoadRequestConfig(job.path(requestFile))
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(job.Options.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	// Later executions continue the logs of the first one
+	logDir := job.logDir()
+	_, statErr := os.Stat(filepath.Join(logDir, history.ManifestFile))
+	resuming := job.Executions > 1 && statErr == nil
+
+	// The manifest makes the job directory usable with report and retry-failed
+	var manifest *history.Manifest
+	if resuming {
+		manifest, err = history.LoadManifest(logDir)
+	} else {
+		if err = os.MkdirAll(logDir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		options := history.Options{
+			Concurrency: job.Options.Concurrency,
+			Timeout:     job.Options.Timeout,
+			DryRun:      job.Options.DryRun,
+		}
+		if job.Options.RateLimit > 0 {
+			options.RateLimit = fmt.Sprintf("%g/s", job.Options.RateLimit)
+		}
+		manifest, err = history.NewManifest(job.path(schemaFile), job.path(requestFile), job.path(csvFile), options)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Rows recorded by earlier executions are not sent again, except rows a
+	// shutdown left unsent or interrupted (cancelled requests)
+	var base JobProgress
+	recorded := make(map[int]bool)
+	if resuming {
+		outcomes, err := history.ReadOutcomes(logDir)
+		if err != nil {
+			return err
+		}
+		for row, outcome := range outcomes {
+			switch {
+			case outcome.Success:
+				base.SuccessRows++
+			case outcome.Attempted() && outcome.ErrorCategory != request.CanceledCategory:
+				base.FailedRows++
+			default:
+				continue
+			}
+			recorded[row] = true
+		}
+	}
+
+	runConfig := &runner.RunConfig{
+		Concurrency: job.Options.Concurrency,
+		RateLimit:   job.Options.RateLimit,
+		Timeout:     timeout,
+	}
+
+	if job.Options.DryRun {
+		var mockConfig *config.MockConfig
+		if _, err := os.Stat(job.path(mockFile)); err == nil {
+			mockConfig, err = config.LoadMockConfig(job.path(mockFile))
+			if err != nil {
+				return err
+			}
+		} else if mockConfig, err = config.DefaultMockConfig(requestConfig); err != nil {
+			return err
+		}
+
+		mockServer := mock.NewServer(mockConfig)
+		if err := mockServer.Start(); err != nil {
+			return err
+		}
+		defer mockServer.Close()
+		runConfig.Transport = mockServer.Transport()
+	}
+
+	runnerInstance, err := runner.NewRunner(schema, requestConfig, runConfig)
+	if err != nil {
+		return err
+	}
+
+	var loggerInstance *logger.Logger
+	if resuming {
+		loggerInstance, err = logger.NewAppendLogger(schema, logDir)
+	} else {
+		loggerInstance, err = logger.NewLogger(schema, logDir)
+	}
+	if err != nil {
+		return err
+	}
+	defer loggerInstance.Close()
+
+	if err := loggerInstance.StartFailedRowExport(job.path(fmt.Sprintf(failedFilePattern, job.Executions)), false); err != nil {
+		return err
+	}
+
+	if err := manifest.Save(logDir); err != nil {
+		return err
+	}
+
+	if resuming {
+		checkpoints, err := history.LoadCheckpoints(logDir)
+		if err != nil {
+			return err
+		}
+		runnerInstance.LoadCheckpoints(checkpoints)
+	}
+
+	m.mu.Lock()
+	if exec := m.executions[job.ID]; exec != nil {
+		exec.runner = runnerInstance
+		exec.base = base
+	}
+	if m.stopping {
+		runnerInstance.Stop()
+	}
+	m.mu.Unlock()
+
+	csvReader := reader.NewCSVReader(schema, job.path(csvFile))
+	if len(recorded) > 0 {
+		csvReader.SetRowFilter(func(rowNumber int) bool {
+			return !recorded[rowNumber]
+		})
+	}
+
+	tasks := make(chan runner.RowTask, job.Options.Concurrency*2)
+	readCtx, stopReading := context.WithCancel(ctx)
+	defer stopReading()
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- csvReader.ReadRows(readCtx, tasks)
+	}()
+
+	result := runnerInstance.Run(ctx, tasks, loggerInstance.LogRequest)
+
+	// Rows read but not sent are recorded as not attempted. Unread rows have
+	// no outcome, so they are sent when the job resumes.
+	stopReading()
+	discarded := runnerInstance.Discard(tasks, loggerInstance.LogRequest)
+	result.NotAttempted += discarded
+	result.TotalRows += discarded
+	readErr := <-readDone
+
+	manifest.AddAttempt(history.Attempt{
+		Command:      "serve",
+		StartedAt:    result.StartTime,
+		FinishedAt:   result.EndTime,
+		TotalRows:    result.TotalRows,
+		SuccessRows:  result.SuccessRows,
+		FailedRows:   result.FailedRows,
+		SkippedRows:  result.SkippedRows,
+		NotAttempted: result.NotAttempted,
+		AbortReason:  result.AbortReason,
+	})
+	if err := manifest.Save(logDir); err != nil {
+		return err
+	}
+	if err := history.SaveCheckpoints(logDir, runnerInstance.GetProcessedHashes()); err != nil {
+		return err
+	}
+
+	if readErr != nil {
+		return readErr
+	}
+	if result.Aborted {
+		return fmt.Errorf("%w: %s", errAborted, result.AbortReason)
+	}
+	return nil
+}
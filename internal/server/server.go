@@ -0,0 +1,347 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"csvfire/internal/history"
+)
+
+// maxUploadMemory is the part of a job upload kept in memory; larger files
+// are buffered on disk by the multipart parser
+const maxUploadMemory = 32 << 20
+
+// maxUploadSize limits the total size of a job upload
+const maxUploadSize = 4 << 30
+
+// eventInterval is how often job events are sent to SSE subscribers
+const eventInterval = time.Second
+
+// downloadableLogs are the run directory files served by the log endpoint
+var downloadableLogs = append([]string{history.ManifestFile, history.CheckpointFile}, history.MergedLogFiles...)
+
+// Server exposes a Manager over an HTTP/JSON API
+type Server struct {
+	manager *Manager
+	token   string
+	mux     *http.ServeMux
+}
+
+// NewServer creates the API server. If token is set, every request must
+// carry it as a bearer token.
+func NewServer(manager *Manager, token string) *Server {
+	s := &Server{
+		manager: manager,
+		token:   token,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /jobs", s.handleCreate)
+	s.mux.HandleFunc("GET /jobs", s.handleList)
+	s.mux.HandleFunc("GET /jobs/{id}", s.handleGet)
+	s.mux.HandleFunc("PATCH /jobs/{id}", s.handleUpdate)
+	s.mux.HandleFunc("POST /jobs/{id}/{action}", s.handleAction)
+	s.mux.HandleFunc("GET /jobs/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("GET /jobs/{id}/logs/{file}", s.handleLog)
+	s.mux.HandleFunc("GET /jobs/{id}/failed", s.handleFailed)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleCreate accepts a multipart upload with schema, request and csv files
+// (and an optional dry-run mock script) plus option fields
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("upload exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid multipart upload: %w", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	options, err := parseJobOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	upload := JobUpload{Name: r.FormValue("name"), Options: options}
+	for _, part := range []struct {
+		field  string
+		target *io.Reader
+	}{
+		{"schema", &upload.Schema},
+		{"request", &upload.Request},
+		{"csv", &upload.CSV},
+		{"mock", &upload.Mock},
+	} {
+		file, err := openFormFile(r.MultipartForm, part.field)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if file != nil {
+			defer file.Close()
+			*part.target = file
+		}
+	}
+
+	job, err := s.manager.Create(upload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if start, _ := strconv.ParseBool(r.FormValue("start")); start {
+		if job, err = s.manager.Start(job.ID); err != nil {
+			writeJobError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, job)
+}
+
+// handleList returns every job
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.List())
+}
+
+// handleGet returns a job with its current progress
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	job, err := s.manager.Get(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleUpdate changes the concurrency or rate of a job, including a running one
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var update JobUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return
+	}
+
+	job, err := s.manager.Update(r.PathValue("id"), update)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleAction starts, pauses, resumes or cancels a job
+func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
+	actions := map[string]func(string) (*Job, error){
+		"start":  s.manager.Start,
+		"pause":  s.manager.Pause,
+		"resume": s.manager.Resume,
+		"cancel": s.manager.Cancel,
+	}
+
+	action, ok := actions[r.PathValue("action")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action '%s'", r.PathValue("action")))
+		return
+	}
+
+	job, err := action(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleEvents streams job snapshots as server-sent events until the job
+// reaches a terminal state or the client disconnects
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, err := s.manager.Get(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(eventInterval)
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(data, last) {
+			fmt.Fprintf(w, "event: job\ndata: %s\n\n", data)
+			flusher.Flush()
+			last = data
+		}
+		if job.State.Terminal() {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		if job, err = s.manager.Get(id); err != nil {
+			return
+		}
+	}
+}
+
+// handleLog downloads a log file of a job
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("file")
+	if !slices.Contains(downloadableLogs, name) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown log file '%s'", name))
+		return
+	}
+
+	path, err := s.manager.LogFile(r.PathValue("id"), name)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("log file '%s' not written yet", name))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, path)
+}
+
+// handleFailed downloads the rows of a job that are still failed
+func (s *Server) handleFailed(w http.ResponseWriter, r *http.Request) {
+	job, err := s.manager.Get(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := writeFailedRows(&buf, job); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.ID+"_failed_rows.csv"))
+	w.Write(buf.Bytes())
+}
+
+// parseJobOptions reads the execution options of an upload
+func parseJobOptions(r *http.Request) (JobOptions, error) {
+	options := JobOptions{Timeout: r.FormValue("timeout")}
+
+	if value := r.FormValue("concurrency"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency <= 0 {
+			return options, fmt.Errorf("invalid concurrency '%s'", value)
+		}
+		options.Concurrency = concurrency
+	}
+
+	// Same format as the CLI (e.g. "5/s")
+	if value := r.FormValue("rate"); value != "" {
+		rate, err := strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+		if err != nil || rate < 0 {
+			return options, fmt.Errorf("invalid rate '%s' (e.g. 5/s)", value)
+		}
+		options.RateLimit = rate
+	}
+
+	if value := r.FormValue("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, fmt.Errorf("invalid dry_run '%s'", value)
+		}
+		options.DryRun = dryRun
+	}
+
+	return options, nil
+}
+
+// openFormFile opens an uploaded file, returning nil if the field is missing
+func openFormFile(form *multipart.Form, field string) (multipart.File, error) {
+	headers := form.File[field]
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	file, err := headers[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded %s: %w", field, err)
+	}
+	return file, nil
+}
+
+// writeJobError maps job operation errors to HTTP status codes
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidJobState):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrInvalidOptions):
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
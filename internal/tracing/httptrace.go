@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attemptTimings collects connection phase timings of a single HTTP attempt
+type attemptTimings struct {
+	mu           sync.Mutex
+	span         trace.Span
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+}
+
+// ClientTrace returns an httptrace.ClientTrace that records DNS, connect, TLS
+// and time-to-first-byte timings on the given span as events and attributes.
+func ClientTrace(span trace.Span) *httptrace.ClientTrace {
+	t := &attemptTimings{span: span, start: time.Now()}
+
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.event("http.get_conn", attribute.String("net.peer", hostPort))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.event("http.got_conn",
+				attribute.Bool("http.conn.reused", info.Reused),
+				attribute.Bool("http.conn.was_idle", info.WasIdle))
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+			t.event("http.dns_start", attribute.String("net.host", info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.duration("http.dns_ms", func() time.Time { return t.dnsStart })
+			if info.Err != nil {
+				t.event("http.dns_error", attribute.String("error", info.Err.Error()))
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.duration("http.connect_ms", func() time.Time { return t.connectStart })
+			if err != nil {
+				t.event("http.connect_error", attribute.String("net.peer", addr), attribute.String("error", err.Error()))
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.duration("http.tls_ms", func() time.Time { return t.tlsStart })
+			if err != nil {
+				t.event("http.tls_error", attribute.String("error", err.Error()))
+			}
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			t.wroteRequest = time.Now()
+			t.mu.Unlock()
+			t.event("http.wrote_request")
+		},
+		GotFirstResponseByte: func() {
+			t.duration("http.ttfb_ms", func() time.Time { return t.start })
+			t.duration("http.server_ms", func() time.Time { return t.wroteRequest })
+		},
+	}
+}
+
+// event adds a span event
+func (t *attemptTimings) event(name string, attrs ...attribute.KeyValue) {
+	t.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// duration records the elapsed time since the given start as a span attribute
+func (t *attemptTimings) duration(key string, since func() time.Time) {
+	t.mu.Lock()
+	start := since()
+	t.mu.Unlock()
+
+	if start.IsZero() {
+		return
+	}
+	t.span.SetAttributes(attribute.Int64(key, time.Since(start).Milliseconds()))
+}
@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/shopspring/decimal"
+
+	"csvfire/internal/config"
+)
+
+// computeColumns evaluates the computed columns of a valid row in schema
+// order and runs their values through the rules of the column, so they are
+// validated, normalized and part of the row data like CSV columns.
+func (v *Validator) computeColumns(rowNum int, result *ValidationResult) {
+	for i := range v.schema.Computed {
+		col := &v.schema.Computed[i]
+
+		value, err := v.evaluateComputed(rowNum, col, result)
+		if err == nil && value == "" {
+			result.Data[col.Name] = ""
+			result.Values[col.Name] = nil
+			if col.Required {
+				err = fmt.Errorf("required field is missing or empty")
+			}
+		} else if err == nil {
+			var normalized string
+			var typedValue any
+			normalized, typedValue, err = v.processValue(value, &col.ColumnSchema)
+			if err == nil {
+				result.Data[col.Name] = normalized
+				result.Values[col.Name] = typedValue
+			}
+		}
+
+		if err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Row:     rowNum,
+				Column:  col.Name,
+				Value:   value,
+				Message: err.Error(),
+			})
+			return // Later computed columns may depend on this one
+		}
+	}
+}
+
+// evaluateComputed runs the expression or template of a computed column and
+// returns its value as text
+func (v *Validator) evaluateComputed(rowNum int, col *config.ComputedColumn, result *ValidationResult) (string, error) {
+	if col.Program != nil {
+		output, err := expr.Run(col.Program, config.ComputedEnv(result.Values, result.Raw, result.Data, rowNum))
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate computed column: %w", err)
+		}
+		return formatComputed(output, &col.ColumnSchema)
+	}
+
+	data := result.TemplateData()
+	if _, exists := data[config.RowNumberField]; !exists {
+		data[config.RowNumberField] = rowNum
+	}
+
+	var buf bytes.Buffer
+	if err := col.TemplateProgram.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render computed column: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// formatComputed formats the result of a computed column expression as text
+// the column type parses; dates use the format of the column
+func formatComputed(value any, col *config.ColumnSchema) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case decimal.Decimal:
+		return v.String(), nil
+	case time.Time:
+		if isDateType(col.Type) {
+			format := col.Format
+			if format == "" {
+				format = "20060102"
+			}
+			return v.Format(format), nil
+		}
+		return v.Format(time.RFC3339), nil
+	case []any:
+		if col.Type == "list" {
+			delimiter := col.Delimiter
+			if delimiter == "" {
+				delimiter = ","
+			}
+			items := make([]string, len(v))
+			for i, item := range v {
+				text, err := formatComputed(item, &config.ColumnSchema{Type: "string"})
+				if err != nil {
+					return "", err
+				}
+				items[i] = text
+			}
+			return strings.Join(items, delimiter), nil
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode computed value: %w", err)
+		}
+		return string(encoded), nil
+	case map[string]any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode computed value: %w", err)
+		}
+		return string(encoded), nil
+	}
+	return fmt.Sprint(value), nil
+}
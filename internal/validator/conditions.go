@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/shopspring/decimal"
+
+	"csvfire/internal/config"
+)
+
+// validateConditions checks required_if, forbidden_if and compare rules once
+// every column of the row has been normalized. Errors belong to the column
+// declaring the rule; columns that already failed are skipped.
+func (v *Validator) validateConditions(rowNum int, result *ValidationResult) {
+	var env map[string]any
+
+	for _, colSchema := range v.schema.Columns {
+		value, processed := result.Values[colSchema.Name]
+		if !processed {
+			continue
+		}
+		empty := value == nil || value == ""
+
+		addError := func(message string) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Row:     rowNum,
+				Column:  colSchema.Name,
+				Value:   result.Raw[colSchema.Name],
+				Message: message,
+			})
+		}
+
+		for _, check := range []struct {
+			cond    *config.Condition
+			failed  bool
+			message string
+		}{
+			{colSchema.RequiredIf, empty, "required field is missing or empty when %s"},
+			{colSchema.ForbiddenIf, !empty, "value must be empty when %s"},
+		} {
+			if check.cond == nil || !check.failed {
+				continue
+			}
+			if env == nil {
+				env = config.ConditionEnv(result.Values, result.Raw, result.Data)
+			}
+			output, err := expr.Run(check.cond.Program, env)
+			if err != nil {
+				addError(fmt.Sprintf("failed to evaluate condition %s: %v", check.cond.Expr, err))
+				continue
+			}
+			if holds, _ := output.(bool); holds {
+				addError(fmt.Sprintf(check.message, check.cond.Expr))
+			}
+		}
+
+		if empty {
+			continue
+		}
+		for _, rule := range colSchema.Compare {
+			other := result.Values[rule.Column]
+			if other == nil || other == "" {
+				continue // Missing or invalid, reported on its own column
+			}
+			cmp, err := compareValues(value, other)
+			if err != nil {
+				addError(fmt.Sprintf("cannot compare with %s: %v", rule.Column, err))
+				continue
+			}
+			if !compareHolds(rule.Op, cmp) {
+				message := rule.Message
+				if message == "" {
+					message = fmt.Sprintf("value must be %s %s (%s)", rule.Op, rule.Column, result.Data[rule.Column])
+				}
+				addError(message)
+			}
+		}
+	}
+}
+
+// compareValues orders two typed values of a row: -1, 0 or 1. Numbers of
+// different types compare by value.
+func compareValues(a, b any) (int, error) {
+	if x, ok := toDecimal(a); ok {
+		if y, ok := toDecimal(b); ok {
+			return x.Cmp(y), nil
+		}
+	}
+
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case y:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("incompatible types %T and %T", a, b)
+}
+
+// toDecimal converts a typed numeric value to a decimal
+func toDecimal(value any) (decimal.Decimal, bool) {
+	switch n := value.(type) {
+	case int64:
+		return decimal.NewFromInt(n), true
+	case float64:
+		return decimal.NewFromFloat(n), true
+	case decimal.Decimal:
+		return n, true
+	}
+	return decimal.Decimal{}, false
+}
+
+// compareHolds reports whether a comparison result satisfies an operator
+func compareHolds(op string, cmp int) bool {
+	switch op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	}
+	return false
+}
@@ -0,0 +1,354 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"csvfire/internal/config"
+	"csvfire/internal/funcs"
+	"csvfire/internal/phone"
+)
+
+// Korean phone number kinds returned by funcs.KoreanPhoneKind
+const (
+	PhoneMobile   = phone.Mobile   // 010, 011, 016-019
+	PhoneLandline = phone.Landline // Area codes 02, 031-064
+	PhoneVoIP     = phone.VoIP     // 070
+	PhoneBusiness = phone.Business // 8-digit representative numbers (15xx, 16xx, 18xx)
+)
+
+// bankAccountLengths lists the usual digit counts of account numbers by bank
+// code. Banks that are not listed accept 10 to 14 digits.
+var bankAccountLengths = map[string][]int{
+	"002": {11, 14},         // KDB산업은행
+	"003": {10, 11, 12, 14}, // IBK기업은행
+	"004": {11, 12, 14},     // KB국민은행
+	"007": {11, 12},         // 수협은행
+	"011": {11, 13},         // NH농협은행
+	"012": {13, 14},         // 지역농·축협
+	"020": {11, 13},         // 우리은행
+	"023": {11},             // SC제일은행
+	"027": {10, 11, 12, 13}, // 한국씨티은행
+	"031": {11, 12},         // iM뱅크(대구)
+	"032": {12, 13},         // 부산은행
+	"034": {12, 13},         // 광주은행
+	"035": {10, 12},         // 제주은행
+	"037": {12, 13},         // 전북은행
+	"039": {12, 13},         // 경남은행
+	"045": {13},             // 새마을금고
+	"048": {13},             // 신협
+	"071": {14},             // 우체국
+	"081": {11, 12, 14},     // 하나은행
+	"088": {11, 12},         // 신한은행
+	"089": {12},             // 케이뱅크
+	"090": {13},             // 카카오뱅크
+	"092": {12},             // 토스뱅크
+}
+
+// Accepted separators in Korean identification and account numbers
+var numberSeparators = strings.NewReplacer("-", "", " ", "")
+
+var allDigits = regexp.MustCompile(`^[0-9]+$`)
+
+// koreanDigits removes hyphens and spaces and returns the digits, or false if
+// the value contains anything else
+func koreanDigits(value string) (string, bool) {
+	digits := numberSeparators.Replace(value)
+	return digits, digits != "" && allDigits.MatchString(digits)
+}
+
+// validateNamedRule checks a value against a named validation rule
+func validateNamedRule(rule config.ValidationRule, value string) error {
+	switch rule.Rule {
+	case "rrn":
+		return ValidateRRN(value)
+	case "frn":
+		return ValidateFRN(value)
+	case "rrn_or_frn":
+		// The gender digit tells which kind of number it is
+		if digits, ok := koreanDigits(value); ok && len(digits) == 13 && strings.ContainsRune("5678", rune(digits[6])) {
+			return ValidateFRN(value)
+		}
+		return ValidateRRN(value)
+	case "brn":
+		return ValidateBRN(value)
+	case "crn":
+		return ValidateCRN(value)
+	case "kr_phone":
+		if funcs.KoreanPhoneKind(value) == "" {
+			return fmt.Errorf("invalid Korean phone number")
+		}
+	case "kr_mobile":
+		if funcs.KoreanPhoneKind(value) != PhoneMobile {
+			return fmt.Errorf("not a Korean mobile number")
+		}
+	case "kr_landline":
+		if funcs.KoreanPhoneKind(value) != PhoneLandline {
+			return fmt.Errorf("not a Korean landline number")
+		}
+	case "kr_postcode":
+		return ValidatePostcode(value)
+	case "phone":
+		return validatePhone(rule, value)
+	case "kr_bank_account":
+		// Accounts whose bank code is in another column are checked with the row
+		if rule.BankColumn == "" {
+			return ValidateBankAccount(rule.Bank, value)
+		}
+	default:
+		return fmt.Errorf("unknown validation rule: %s", rule.Rule)
+	}
+	return nil
+}
+
+// residentDigits checks the shape of a 주민등록번호 or 외국인등록번호 and the
+// birth date encoded in it, and returns its digits
+func residentDigits(value string, genderDigits string) (string, error) {
+	digits, ok := koreanDigits(value)
+	if !ok || len(digits) != 13 {
+		return "", fmt.Errorf("must be 13 digits")
+	}
+	gender := digits[6]
+	if !strings.ContainsRune(genderDigits, rune(gender)) {
+		return "", fmt.Errorf("invalid gender digit: %c", gender)
+	}
+
+	century := "19"
+	switch gender {
+	case '3', '4', '7', '8':
+		century = "20"
+	case '9', '0':
+		century = "18"
+	}
+	if _, err := time.Parse("20060102", century+digits[:6]); err != nil {
+		return "", fmt.Errorf("invalid birth date: %s", digits[:6])
+	}
+	return digits, nil
+}
+
+// residentChecksum returns the weighted digit sum of a resident number
+func residentChecksum(digits string) int {
+	weights := []int{2, 3, 4, 5, 6, 7, 8, 9, 2, 3, 4, 5}
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	return sum
+}
+
+// rrnRandomSince is the first birth month of 주민등록번호 issued without a
+// check digit; from October 2020 the last seven digits are random
+const rrnRandomSince = "2010"
+
+// ValidateRRN validates a 주민등록번호 (YYMMDD-GNNNNNC) and, for numbers
+// issued before October 2020, its check digit
+func ValidateRRN(value string) error {
+	digits, err := residentDigits(value, "123490")
+	if err != nil {
+		return fmt.Errorf("invalid resident registration number: %w", err)
+	}
+	if gender := digits[6]; (gender == '3' || gender == '4') && digits[:4] >= rrnRandomSince {
+		return nil
+	}
+	check := (11 - residentChecksum(digits)%11) % 10
+	if int(digits[12]-'0') != check {
+		return fmt.Errorf("invalid resident registration number: checksum mismatch")
+	}
+	return nil
+}
+
+// ValidateFRN validates a 외국인등록번호 (gender digit 5-8) and its check digit
+func ValidateFRN(value string) error {
+	digits, err := residentDigits(value, "5678")
+	if err != nil {
+		return fmt.Errorf("invalid foreigner registration number: %w", err)
+	}
+	check := (13 - residentChecksum(digits)%11) % 10
+	if int(digits[12]-'0') != check {
+		return fmt.Errorf("invalid foreigner registration number: checksum mismatch")
+	}
+	return nil
+}
+
+// ValidateBRN validates a 사업자등록번호 (NNN-NN-NNNNC) and its check digit
+func ValidateBRN(value string) error {
+	digits, ok := koreanDigits(value)
+	if !ok || len(digits) != 10 {
+		return fmt.Errorf("invalid business registration number: must be 10 digits")
+	}
+	weights := []int{1, 3, 7, 1, 3, 7, 1, 3, 5}
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	sum += int(digits[8]-'0') * 5 / 10
+	check := (10 - sum%10) % 10
+	if int(digits[9]-'0') != check {
+		return fmt.Errorf("invalid business registration number: checksum mismatch")
+	}
+	return nil
+}
+
+// ValidateCRN validates a 법인등록번호 (NNNNNN-NNNNNNC) and its check digit
+func ValidateCRN(value string) error {
+	digits, ok := koreanDigits(value)
+	if !ok || len(digits) != 13 {
+		return fmt.Errorf("invalid corporation registration number: must be 13 digits")
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]-'0') * (i%2 + 1)
+	}
+	check := (10 - sum%10) % 10
+	if int(digits[12]-'0') != check {
+		return fmt.Errorf("invalid corporation registration number: checksum mismatch")
+	}
+	return nil
+}
+
+// ValidatePostcode validates a 5-digit 우편번호 (국가기초구역번호)
+func ValidatePostcode(value string) error {
+	if len(value) != 5 || !allDigits.MatchString(value) {
+		return fmt.Errorf("invalid postcode: must be 5 digits")
+	}
+	if value < "01000" || value > "63999" {
+		return fmt.Errorf("invalid postcode: out of range")
+	}
+	return nil
+}
+
+// ValidateBankAccount validates the digit count of an account number for a
+// bank code
+func ValidateBankAccount(bankCode, value string) error {
+	digits, ok := koreanDigits(value)
+	if !ok {
+		return fmt.Errorf("invalid bank account: only digits and hyphens are allowed")
+	}
+	lengths, known := bankAccountLengths[bankCode]
+	if !known {
+		if len(digits) < 10 || len(digits) > 14 {
+			return fmt.Errorf("invalid bank account: must be 10-14 digits")
+		}
+		return nil
+	}
+	for _, length := range lengths {
+		if len(digits) == length {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid bank account for bank %s: %d digits (expected %s)",
+		bankCode, len(digits), joinInts(lengths, " or "))
+}
+
+// joinInts formats numbers separated by sep
+func joinInts(numbers []int, sep string) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, sep)
+}
+
+// formatKoreanPhone formats a Korean phone number with hyphens in national
+// form; values that are not Korean phone numbers are returned as they are
+func formatKoreanPhone(value string) string {
+	number, err := phone.Parse(value, "KR")
+	if err != nil || number.Region != "KR" {
+		return value
+	}
+	return number.Format(phone.FormatNational)
+}
+
+// formatRRN formats a 13-digit resident or foreigner number as YYMMDD-NNNNNNN
+func formatRRN(value string) string {
+	digits, ok := koreanDigits(value)
+	if !ok || len(digits) != 13 {
+		return value
+	}
+	return digits[:6] + "-" + digits[6:]
+}
+
+// formatBRN formats a 사업자등록번호 as NNN-NN-NNNNN
+func formatBRN(value string) string {
+	digits, ok := koreanDigits(value)
+	if !ok || len(digits) != 10 {
+		return value
+	}
+	return digits[:3] + "-" + digits[3:5] + "-" + digits[5:]
+}
+
+// formatCRN formats a 법인등록번호 as NNNNNN-NNNNNNN
+func formatCRN(value string) string {
+	return formatRRN(value)
+}
+
+// Full names of 시·도 written in short forms
+var regionNames = map[string]string{
+	"서울": "서울특별시", "서울시": "서울특별시",
+	"부산": "부산광역시", "부산시": "부산광역시",
+	"대구": "대구광역시", "대구시": "대구광역시",
+	"인천": "인천광역시", "인천시": "인천광역시",
+	"광주": "광주광역시", "광주시": "광주광역시",
+	"대전": "대전광역시", "대전시": "대전광역시",
+	"울산": "울산광역시", "울산시": "울산광역시",
+	"세종": "세종특별자치시", "세종시": "세종특별자치시",
+	"경기": "경기도",
+	"강원": "강원특별자치도", "강원도": "강원특별자치도",
+	"충북": "충청북도",
+	"충남": "충청남도",
+	"전북": "전북특별자치도", "전라북도": "전북특별자치도",
+	"전남": "전라남도",
+	"경북": "경상북도",
+	"경남": "경상남도",
+	"제주": "제주특별자치도", "제주도": "제주특별자치도",
+}
+
+var (
+	addressSpaces      = regexp.MustCompile(`\s+`)
+	addressHyphen      = regexp.MustCompile(`(\d)\s*-\s*(\d)`)
+	addressRoadNumber  = regexp.MustCompile(`([가-힣A-Za-z0-9.]+(?:로|길))(\d+(?:-\d+)?)($|[\s,(])`)
+	addressParenthesis = regexp.MustCompile(`\s*\(\s*([^)]*?)\s*\)`)
+	addressComma       = regexp.MustCompile(`\s*,\s*`)
+)
+
+// normalizeRoadAddress normalizes the spelling of a 도로명주소: repeated
+// spaces, short 시·도 names ("서울" → "서울특별시"), the space between the
+// road name and the building number ("테헤란로152" → "테헤란로 152"), and
+// spacing around hyphens, commas and the parenthesized 참고항목.
+func normalizeRoadAddress(value string) string {
+	address := addressSpaces.ReplaceAllString(strings.TrimSpace(value), " ")
+	if address == "" {
+		return address
+	}
+
+	region, rest, _ := strings.Cut(address, " ")
+	if full, ok := regionNames[region]; ok {
+		address = strings.TrimSpace(full + " " + rest)
+	}
+
+	address = addressHyphen.ReplaceAllString(address, "$1-$2")
+	address = addressRoadNumber.ReplaceAllString(address, "$1 $2$3")
+	address = addressComma.ReplaceAllString(address, ", ")
+	address = addressParenthesis.ReplaceAllString(address, " ($1)")
+	return strings.TrimSpace(address)
+}
+
+// validatePhone checks a phone number against the phone rule options
+func validatePhone(rule config.ValidationRule, value string) error {
+	number, err := phone.Parse(value, rule.DefaultRegion)
+	if err != nil {
+		return err
+	}
+	if len(rule.Regions) > 0 && !slices.ContainsFunc(rule.Regions, func(region string) bool {
+		return strings.EqualFold(region, number.Region)
+	}) {
+		return fmt.Errorf("phone number region %s is not allowed (allowed: %s)", number.Region, strings.Join(rule.Regions, ", "))
+	}
+	if rule.PhoneType != "" && !number.IsType(rule.PhoneType) {
+		return fmt.Errorf("phone number is %s, not %s", number.Type, rule.PhoneType)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package validator
+
+// enrich adds the fields of the lookup rows matching the row to its typed
+// values, for templates. Fields are nil when the column is empty or has no
+// match.
+func (v *Validator) enrich(result *ValidationResult) {
+	for _, rule := range v.schema.Enrich {
+		table, column := v.schema.FindLookup(rule.Lookup)
+
+		var match map[string]string
+		if key := result.Data[rule.Column]; key != "" {
+			match, _ = table.Find(column, key)
+		}
+
+		for field, lookupColumn := range rule.Fields {
+			if match == nil {
+				result.Values[field] = nil
+			} else {
+				result.Values[field] = match[lookupColumn]
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/expr-lang/expr"
+
+	"csvfire/internal/config"
+)
+
+// ValidatorFunc checks a value for a custom validation rule; params are the
+// params of the rule in the schema
+type ValidatorFunc func(value string, params map[string]any) error
+
+// TransformFunc converts a value for a custom transform; params are the params
+// of the transform in the schema
+type TransformFunc func(value string, params map[string]any) (string, error)
+
+var (
+	registryMu sync.RWMutex
+	validators = make(map[string]ValidatorFunc)
+	transforms = make(map[string]TransformFunc)
+)
+
+// RegisterValidator makes a validation rule available to schemas as
+// `rule: <name>`. It is meant to be called from an init function; it panics if
+// the name is empty, is a built-in rule or is registered twice.
+func RegisterValidator(name string, fn ValidatorFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || fn == nil {
+		panic("validator: RegisterValidator needs a name and a function")
+	}
+	if slices.Contains(config.ValidationRuleNames, name) {
+		panic("validator: RegisterValidator called for built-in rule " + name)
+	}
+	if _, dup := validators[name]; dup {
+		panic("validator: RegisterValidator called twice for " + name)
+	}
+
+	validators[name] = fn
+	config.RegisterValidationRuleName(name)
+}
+
+// RegisterTransform makes a transform available to schemas as `rule: <name>`
+// in a transform list. It is meant to be called from an init function; it
+// panics if the name is empty or is registered twice.
+func RegisterTransform(name string, fn TransformFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || fn == nil {
+		panic("validator: RegisterTransform needs a name and a function")
+	}
+	if _, dup := transforms[name]; dup {
+		panic("validator: RegisterTransform called twice for " + name)
+	}
+
+	transforms[name] = fn
+	config.RegisterTransformName(name)
+}
+
+// validateRule checks a value against a built-in, registered or scripted rule
+func (v *Validator) validateRule(rule config.ValidationRule, value string) error {
+	if script := v.schema.Rules.Validators[rule.Rule]; script != nil {
+		output, err := expr.Run(script.Program, config.ScriptEnv(value, rule.Params))
+		if err != nil {
+			return fmt.Errorf("rule '%s' failed: %w", rule.Rule, err)
+		}
+		if ok, _ := output.(bool); !ok {
+			if script.Message != "" {
+				return fmt.Errorf("%s", script.Message)
+			}
+			return fmt.Errorf("value does not satisfy rule '%s'", rule.Rule)
+		}
+		return nil
+	}
+
+	registryMu.RLock()
+	fn := validators[rule.Rule]
+	registryMu.RUnlock()
+	if fn != nil {
+		return fn(value, rule.Params)
+	}
+
+	return validateNamedRule(rule, value)
+}
+
+// transformRule converts a value with a registered or scripted transform
+func (v *Validator) transformRule(rule config.TransformRule, value string) (string, error) {
+	if script := v.schema.Rules.Transforms[rule.Rule]; script != nil {
+		output, err := expr.Run(script.Program, config.ScriptEnv(value, rule.Params))
+		if err != nil {
+			return "", fmt.Errorf("transform '%s' failed: %w", rule.Rule, err)
+		}
+		result, ok := output.(string)
+		if !ok {
+			return "", fmt.Errorf("transform '%s' returned %T instead of a string", rule.Rule, output)
+		}
+		return result, nil
+	}
+
+	registryMu.RLock()
+	fn := transforms[rule.Rule]
+	registryMu.RUnlock()
+	if fn == nil {
+		return "", fmt.Errorf("unknown transform: %s", rule.Rule)
+	}
+
+	result, err := fn(value, rule.Params)
+	if err != nil {
+		return "", fmt.Errorf("transform '%s' failed: %w", rule.Rule, err)
+	}
+	return result, nil
+}
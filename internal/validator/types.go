@@ -0,0 +1,225 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"csvfire/internal/config"
+)
+
+// Accepted spellings of bool values (compared case-insensitively)
+var (
+	trueValues  = []string{"true", "t", "y", "yes", "1", "예", "네"}
+	falseValues = []string{"false", "f", "n", "no", "0", "아니오", "아니요"}
+)
+
+// uuidPattern matches a UUID with or without hyphens and braces
+var uuidPattern = regexp.MustCompile(`^\{?([0-9a-fA-F]{8})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{12})\}?$`)
+
+// isDateType reports whether a column type is a date (not a datetime)
+func isDateType(colType string) bool {
+	return colType != "datetime" && strings.HasPrefix(colType, "date")
+}
+
+// parseValue converts a value to the Go type of its column. It returns the
+// normalized string and the typed value. Types that have a canonical form
+// (bool, email, uuid, datetime, json, list) are normalized to it; the other
+// types keep the value as it is.
+func (v *Validator) parseValue(value string, colSchema *config.ColumnSchema) (string, any, error) {
+	colType := colSchema.Type
+	switch {
+	case colType == "string":
+		return value, value, nil
+	case colType == "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid integer: %w", err)
+		}
+		return value, n, nil
+	case colType == "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid float: %w", err)
+		}
+		return value, f, nil
+	case strings.HasPrefix(colType, "decimal("):
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid decimal: %w", err)
+		}
+		return value, d, nil
+	case colType == "bool":
+		b, err := parseBool(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return strconv.FormatBool(b), b, nil
+	case colType == "email":
+		email, err := normalizeEmail(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return email, email, nil
+	case colType == "uuid":
+		match := uuidPattern.FindStringSubmatch(value)
+		if match == nil {
+			return "", nil, fmt.Errorf("invalid UUID")
+		}
+		id := strings.ToLower(strings.Join(match[1:], "-"))
+		return id, id, nil
+	case colType == "datetime":
+		t, err := parseDatetime(value, colSchema)
+		if err != nil {
+			return "", nil, err
+		}
+		return t.Format(time.RFC3339), t, nil
+	case colType == "json":
+		return parseJSON(value)
+	case colType == "list":
+		return v.parseList(value, colSchema)
+	case isDateType(colType):
+		format := colSchema.Format
+		if format == "" {
+			format = "20060102" // Default YYYYMMDD
+		}
+		date, err := time.Parse(format, value)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid date format: %w", err)
+		}
+		return value, date, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported column type: %s", colType)
+	}
+}
+
+// parseBool parses Y/N, 1/0, true/false and 예/아니오 style values
+func parseBool(value string) (bool, error) {
+	lowered := strings.ToLower(value)
+	for _, candidate := range trueValues {
+		if lowered == candidate {
+			return true, nil
+		}
+	}
+	for _, candidate := range falseValues {
+		if lowered == candidate {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("invalid bool: %s (use Y/N, 1/0, true/false or 예/아니오)", value)
+}
+
+// normalizeEmail validates a bare email address and lowercases its domain
+func normalizeEmail(value string) (string, error) {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Name != "" || address.Address != value {
+		return "", fmt.Errorf("invalid email address")
+	}
+	local, domain, _ := strings.Cut(address.Address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", fmt.Errorf("invalid email domain: %s", domain)
+	}
+	return local + "@" + strings.ToLower(domain), nil
+}
+
+// parseDatetime parses a datetime with the column layout (default RFC3339).
+// Values without an offset are read in the column timezone, and the result
+// is converted to it (default UTC). The timezone is loaded with the schema.
+func parseDatetime(value string, colSchema *config.ColumnSchema) (time.Time, error) {
+	layout := colSchema.Format
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	location := colSchema.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	t, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid datetime format: %w", err)
+	}
+	return t.In(location), nil
+}
+
+// parseJSON validates a JSON value and re-serializes it compactly. The typed
+// value is the decoded JSON with numbers as int64 or float64.
+func parseJSON(value string) (string, any, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(value)); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(compacted.Bytes()))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return compacted.String(), convertJSONNumbers(decoded), nil
+}
+
+// convertJSONNumbers replaces json.Number values with int64 or float64
+func convertJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = convertJSONNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = convertJSONNumbers(item)
+		}
+	}
+	return value
+}
+
+// parseList splits a list value and runs each item through the item rules.
+// Items are trimmed and empty items are ignored; the normalized value joins
+// the normalized items with the delimiter.
+func (v *Validator) parseList(value string, colSchema *config.ColumnSchema) (string, any, error) {
+	delimiter := colSchema.Delimiter
+	if delimiter == "" {
+		delimiter = ","
+	}
+	itemSchema := colSchema.Items
+	if itemSchema == nil {
+		itemSchema = &config.ColumnSchema{Type: "string"}
+	}
+
+	normalized := make([]string, 0)
+	items := make([]any, 0)
+	for _, item := range strings.Split(value, delimiter) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		normalizedItem, typedItem, err := v.processValue(item, itemSchema)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid list item %q: %w", item, err)
+		}
+		normalized = append(normalized, normalizedItem)
+		items = append(items, typedItem)
+	}
+
+	if colSchema.MinItems != nil && len(items) < *colSchema.MinItems {
+		return "", nil, fmt.Errorf("too few items (min %d)", *colSchema.MinItems)
+	}
+	if colSchema.MaxItems != nil && len(items) > *colSchema.MaxItems {
+		return "", nil, fmt.Errorf("too many items (max %d)", *colSchema.MaxItems)
+	}
+	return strings.Join(normalized, delimiter), items, nil
+}
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"csvfire/internal/config"
+)
+
+func TestDatetimeTimezone(t *testing.T) {
+	schema, err := config.ParseSchema([]byte(`
+version: 1
+columns:
+  - name: at
+    type: datetime
+    format: "2006-01-02 15:04"
+    timezone: Asia/Seoul
+`), ".")
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	result := NewValidator(schema).ValidateRow(1, map[string]string{"at": "2024-01-01 09:00"})
+	if !result.Valid {
+		t.Fatalf("row is invalid: %v", result.Errors)
+	}
+	if got, want := result.Data["at"], "2024-01-01T09:00:00+09:00"; got != want {
+		t.Errorf("normalized value = %s, want %s", got, want)
+	}
+	at, ok := result.Values["at"].(time.Time)
+	if !ok {
+		t.Fatalf("typed value is %T, want time.Time", result.Values["at"])
+	}
+	if got := at.Location().String(); got != "Asia/Seoul" {
+		t.Errorf("location = %s, want Asia/Seoul", got)
+	}
+}
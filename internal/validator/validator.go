@@ -5,11 +5,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/shopspring/decimal"
-
 	"csvfire/internal/config"
+	"csvfire/internal/funcs"
+	"csvfire/internal/phone"
 )
 
 // ValidationError represents a validation error
@@ -25,11 +26,38 @@ type ValidationResult struct {
 	Valid  bool              `json:"valid"`
 	Errors []ValidationError `json:"errors"`
 	Data   map[string]string `json:"data"` // Processed and normalized data
+	Raw    map[string]string `json:"raw"`  // Original values as read from the CSV
+
+	// Values holds the normalized data as typed values: int64, float64,
+	// decimal.Decimal, time.Time or string, and nil for nulls
+	Values map[string]any `json:"-"`
+}
+
+// TemplateData returns the data templates are executed with: the typed value
+// of each column and enrich field, the normalized strings under "norm" and the
+// original CSV values under "raw". Columns named "norm" or "raw" take
+// precedence.
+func (r *ValidationResult) TemplateData() map[string]any {
+	data := make(map[string]any, len(r.Values)+2)
+	data["raw"] = r.Raw
+	data["norm"] = r.Data
+	if r.Values == nil {
+		// Rows that were not validated only have strings
+		for key, value := range r.Data {
+			data[key] = value
+		}
+		return data
+	}
+	for key, value := range r.Values {
+		data[key] = value
+	}
+	return data
 }
 
 // Validator handles validation and normalization of CSV data
 type Validator struct {
 	schema *config.Schema
+	seenMu sync.Mutex
 	seen   map[string]map[string]bool // For uniqueness tracking: column -> value -> seen
 }
 
@@ -54,10 +82,37 @@ func NewValidator(schema *config.Schema) *Validator {
 
 // ValidateRow validates a single row of CSV data
 func (v *Validator) ValidateRow(rowNum int, data map[string]string) *ValidationResult {
+	result := v.processRow(rowNum, data)
+
+	// Check uniqueness constraints
+	if result.Valid {
+		v.checkUniqueness(rowNum, result)
+	}
+
+	// Validate row-level rules
+	if result.Valid {
+		v.validateRowRules(rowNum, result)
+	}
+
+	return result
+}
+
+// Normalize returns the normalized values of a row without the uniqueness
+// and row-level checks. It keeps no state, so a row may be normalized any
+// number of times alongside validation, e.g. to select rows.
+func (v *Validator) Normalize(rowNum int, data map[string]string) map[string]string {
+	return v.processRow(rowNum, data).Data
+}
+
+// processRow validates and normalizes the values of a row and adds its
+// enriched and computed fields
+func (v *Validator) processRow(rowNum int, data map[string]string) *ValidationResult {
 	result := &ValidationResult{
 		Valid:  true,
 		Errors: make([]ValidationError, 0),
 		Data:   make(map[string]string),
+		Raw:    data,
+		Values: make(map[string]any),
 	}
 
 	// Process each column according to schema
@@ -65,9 +120,11 @@ func (v *Validator) ValidateRow(rowNum int, data map[string]string) *ValidationR
 		value, exists := data[colSchema.Name]
 		
 		// Handle null policy
+		null := !exists
 		if v.schema.NullPolicy.TreatEmptyAsNull && value == "" {
 			value = ""
 			exists = false
+			null = true
 		}
 
 		// Check required fields
@@ -82,24 +139,20 @@ func (v *Validator) ValidateRow(rowNum int, data map[string]string) *ValidationR
 			continue
 		}
 
-		// Skip validation for empty non-required fields
+		// Skip validation for empty non-required fields. An empty value is
+		// only kept as a string in string columns; otherwise it is a null.
 		if !exists || value == "" {
 			result.Data[colSchema.Name] = ""
-			continue
-		}
-
-		// Apply preprocessing
-		processedValue := v.preprocess(value, colSchema.Preprocess)
-
-		// Apply normalization
-		if colSchema.Normalize != nil {
-			if mapped, ok := colSchema.Normalize.Map[processedValue]; ok {
-				processedValue = mapped
+			if null || colSchema.Type != "string" {
+				result.Values[colSchema.Name] = nil
+			} else {
+				result.Values[colSchema.Name] = ""
 			}
+			continue
 		}
 
-		// Validate the processed value
-		if err := v.validateValue(processedValue, &colSchema); err != nil {
+		normalizedValue, typedValue, err := v.processValue(value, &colSchema)
+		if err != nil {
 			result.Valid = false
 			result.Errors = append(result.Errors, ValidationError{
 				Row:     rowNum,
@@ -110,25 +163,73 @@ func (v *Validator) ValidateRow(rowNum int, data map[string]string) *ValidationR
 			continue
 		}
 
-		// Apply transformations
-		transformedValue := v.transform(processedValue, colSchema.Transform)
-
-		result.Data[colSchema.Name] = transformedValue
+		result.Data[colSchema.Name] = normalizedValue
+		result.Values[colSchema.Name] = typedValue
 	}
 
-	// Check uniqueness constraints
-	if result.Valid {
-		v.checkUniqueness(rowNum, result)
-	}
+	// Check account numbers against the bank code column
+	v.validateBankAccounts(rowNum, result)
 
-	// Validate row-level rules
+	// Check required_if, forbidden_if and compare rules across columns
+	v.validateConditions(rowNum, result)
+
+	// Add fields from reference data
+	v.enrich(result)
+
+	// Evaluate computed columns from the validated row
 	if result.Valid {
-		v.validateRowRules(rowNum, result)
+		v.computeColumns(rowNum, result)
 	}
 
 	return result
 }
 
+// processValue runs a non-empty value through the rules of its column:
+// preprocessing, normalization, validation and transformation. It returns the
+// normalized string and the typed value.
+func (v *Validator) processValue(value string, colSchema *config.ColumnSchema) (string, any, error) {
+	// Apply preprocessing
+	processedValue := v.preprocess(value, colSchema.Preprocess)
+
+	// Apply normalization
+	if colSchema.Normalize != nil {
+		if mapped, ok := colSchema.Normalize.Map[processedValue]; ok {
+			processedValue = mapped
+		}
+	}
+
+	// Parse the value by its column type, then validate it
+	normalized, typedValue, err := v.parseValue(processedValue, colSchema)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := v.validateValue(processedValue, typedValue, colSchema); err != nil {
+		return "", nil, err
+	}
+
+	// Apply transformations; a changed value is parsed again
+	transformedValue, err := v.transform(processedValue, colSchema.Transform)
+	if err != nil {
+		return "", nil, err
+	}
+	if transformedValue != processedValue {
+		normalized, typedValue, err = v.parseValue(transformedValue, colSchema)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	// Reference data check on the normalized value
+	if colSchema.InLookup != "" {
+		table, column := v.schema.FindLookup(colSchema.InLookup)
+		if !table.Has(column, normalized) {
+			return "", nil, fmt.Errorf("value not found in %s", colSchema.InLookup)
+		}
+	}
+
+	return normalized, typedValue, nil
+}
+
 // preprocess applies preprocessing rules to a value
 func (v *Validator) preprocess(value string, rules []config.PreprocessRule) string {
 	result := value
@@ -153,11 +254,14 @@ func (v *Validator) preprocess(value string, rules []config.PreprocessRule) stri
 	return result
 }
 
-// validateValue validates a single value against column schema
-func (v *Validator) validateValue(value string, colSchema *config.ColumnSchema) error {
-	// Type validation
-	if err := v.validateType(value, colSchema.Type, colSchema.Format); err != nil {
-		return err
+// validateValue validates a single value against column schema; typedValue
+// is the value parsed by its column type
+func (v *Validator) validateValue(value string, typedValue any, colSchema *config.ColumnSchema) error {
+	// Date validation
+	if date, ok := typedValue.(time.Time); ok && isDateType(colSchema.Type) {
+		if err := v.validateDate(date, colSchema.Format); err != nil {
+			return err
+		}
 	}
 
 	// Length constraints
@@ -195,6 +299,14 @@ func (v *Validator) validateValue(value string, colSchema *config.ColumnSchema)
 
 	// Custom validation rules
 	for _, rule := range colSchema.Validators {
+		if rule.Rule != "" {
+			if err := v.validateRule(rule, value); err != nil {
+				if rule.Message != "" {
+					return fmt.Errorf("%s", rule.Message)
+				}
+				return err
+			}
+		}
 		if rule.Regex != "" {
 			matched, err := regexp.MatchString(rule.Regex, value)
 			if err != nil {
@@ -205,7 +317,7 @@ func (v *Validator) validateValue(value string, colSchema *config.ColumnSchema)
 				if message == "" {
 					message = "value does not match validation rule"
 				}
-				return fmt.Errorf(message)
+				return fmt.Errorf("%s", message)
 			}
 		}
 	}
@@ -213,47 +325,10 @@ func (v *Validator) validateValue(value string, colSchema *config.ColumnSchema)
 	return nil
 }
 
-// validateType validates value against the specified type
-func (v *Validator) validateType(value, colType, format string) error {
-	switch {
-	case colType == "string":
-		return nil // No additional validation needed
-	case colType == "int":
-		_, err := strconv.Atoi(value)
-		if err != nil {
-			return fmt.Errorf("invalid integer: %w", err)
-		}
-	case colType == "float":
-		_, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return fmt.Errorf("invalid float: %w", err)
-		}
-	case strings.HasPrefix(colType, "decimal("):
-		_, err := decimal.NewFromString(value)
-		if err != nil {
-			return fmt.Errorf("invalid decimal: %w", err)
-		}
-	case strings.HasPrefix(colType, "date"):
-		return v.validateDate(value, format)
-	default:
-		return fmt.Errorf("unsupported column type: %s", colType)
-	}
-	return nil
-}
-
 // validateDate validates date values
-func (v *Validator) validateDate(value, format string) error {
-	if format == "" {
-		format = "20060102" // Default YYYYMMDD
-	}
-
-	date, err := time.Parse(format, value)
-	if err != nil {
-		return fmt.Errorf("invalid date format: %w", err)
-	}
-
+func (v *Validator) validateDate(date time.Time, format string) error {
 	// Additional validation for Korean birth dates (age 0-120)
-	if format == "20060102" {
+	if format == "" || format == "20060102" {
 		now := time.Now()
 		age := now.Year() - date.Year()
 		if date.After(now.AddDate(-age, 0, 0)) {
@@ -269,37 +344,96 @@ func (v *Validator) validateDate(value, format string) error {
 }
 
 // transform applies transformation rules to a value
-func (v *Validator) transform(value string, rules []config.TransformRule) string {
+func (v *Validator) transform(value string, rules []config.TransformRule) (string, error) {
 	result := value
 
 	for _, rule := range rules {
+		if rule.Rule != "" {
+			transformed, err := v.transformRule(rule, result)
+			if err != nil {
+				return "", err
+			}
+			result = transformed
+		}
+		if rule.PhoneE164 != nil {
+			result = formatPhone(result, rule.PhoneE164)
+		}
 		if rule.FormatKoreanPhoneE164 {
 			result = formatKoreanPhoneE164(result)
 		}
+		if rule.FormatKoreanPhone {
+			result = formatKoreanPhone(result)
+		}
+		if rule.FormatRRN {
+			result = formatRRN(result)
+		}
+		if rule.MaskRRN {
+			result = funcs.MaskRRN(result)
+		}
+		if rule.FormatBRN {
+			result = formatBRN(result)
+		}
+		if rule.FormatCRN {
+			result = formatCRN(result)
+		}
+		if rule.NormalizeRoadAddress {
+			result = normalizeRoadAddress(result)
+		}
 	}
 
-	return result
+	return result, nil
 }
 
-// formatKoreanPhoneE164 formats Korean phone numbers to E164 format
-func formatKoreanPhoneE164(phone string) string {
-	// Remove all non-digit characters
-	cleaned := regexp.MustCompile(`\D`).ReplaceAllString(phone, "")
-	
-	// Handle Korean phone numbers
-	if len(cleaned) == 10 && strings.HasPrefix(cleaned, "0") {
-		// 010-XXXX-XXXX format -> +82-10-XXXX-XXXX
-		return "+82" + cleaned[1:]
-	} else if len(cleaned) == 11 && strings.HasPrefix(cleaned, "01") {
-		// 010-XXXX-XXXX format -> +82-10-XXXX-XXXX
-		return "+82" + cleaned[1:]
+// formatKoreanPhoneE164 formats Korean phone numbers to E164 format; values
+// that are not valid phone numbers are returned as they are
+func formatKoreanPhoneE164(value string) string {
+	return formatPhone(value, &config.PhoneFormatRule{DefaultRegion: "KR"})
+}
+
+// formatPhone formats a phone number for the phone_e164 transform; values
+// that are not valid phone numbers are returned as they are
+func formatPhone(value string, rule *config.PhoneFormatRule) string {
+	number, err := phone.Parse(value, rule.DefaultRegion)
+	if err != nil {
+		return value
+	}
+	return number.Format(rule.Format)
+}
+
+// validateBankAccounts checks kr_bank_account rules whose bank code is read
+// from another column of the row
+func (v *Validator) validateBankAccounts(rowNum int, result *ValidationResult) {
+	for _, colSchema := range v.schema.Columns {
+		account := result.Data[colSchema.Name]
+		if account == "" {
+			continue // Empty or already invalid
+		}
+		for _, rule := range colSchema.Validators {
+			if rule.Rule != "kr_bank_account" || rule.BankColumn == "" {
+				continue
+			}
+			if err := ValidateBankAccount(result.Data[rule.BankColumn], account); err != nil {
+				message := err.Error()
+				if rule.Message != "" {
+					message = rule.Message
+				}
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Row:     rowNum,
+					Column:  colSchema.Name,
+					Value:   result.Raw[colSchema.Name],
+					Message: message,
+				})
+			}
+		}
 	}
-	
-	return cleaned // Return as-is if not a standard Korean mobile format
 }
 
 // checkUniqueness validates uniqueness constraints
 func (v *Validator) checkUniqueness(rowNum int, result *ValidationResult) {
+	v.seenMu.Lock()
+	defer v.seenMu.Unlock()
+
 	for _, rule := range v.schema.Uniqueness {
 		for _, col := range rule.Columns {
 			value := result.Data[col]